@@ -8,6 +8,7 @@ import (
 	"my-ai-app/model"
 	"my-ai-app/service"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,11 +16,29 @@ import (
 
 type UploadHandler struct {
 	analysisService *service.AnalysisService
+	limits          UploadLimits
 }
 
 func NewUploadHandler(cfg *config.Config) *UploadHandler {
 	return &UploadHandler{
 		analysisService: service.NewAnalysisService(cfg),
+		limits: UploadLimits{
+			MaxFiles:     cfg.MaxUploadFiles,
+			MaxFileBytes: cfg.MaxUploadFileBytes,
+			MaxPixels:    cfg.MaxImagePixels,
+		},
+	}
+}
+
+// Close 写入尚未落盘的判定缓存与申请记录（服务退出时调用）
+func (h *UploadHandler) Close() {
+	h.analysisService.Close()
+}
+
+// limitRequestBody 限制请求体整体大小，超限时解析表单会返回 http.MaxBytesError
+func (h *UploadHandler) limitRequestBody(c *gin.Context) {
+	if limit := h.limits.maxRequestBytes(); limit > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
 	}
 }
 
@@ -31,7 +50,8 @@ func (h *UploadHandler) AnalyzeQwen(c *gin.Context) {
 	appData, fileHeaders, err := h.bindRequest(c)
 	if err != nil {
 		log.Printf("Qwen请求绑定失败: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求无效", "details": err.Error()})
+		reqErr := asRequestError(err)
+		c.JSON(reqErr.Status, gin.H{"error": "请求无效", "code": reqErr.Code, "details": reqErr.Message})
 		return
 	}
 
@@ -56,7 +76,8 @@ func (h *UploadHandler) AnalyzeVolcano(c *gin.Context) {
 	appData, fileHeaders, err := h.bindRequest(c)
 	if err != nil {
 		log.Printf("Volcano请求绑定失败: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求无效", "details": err.Error()})
+		reqErr := asRequestError(err)
+		c.JSON(reqErr.Status, gin.H{"error": "请求无效", "code": reqErr.Code, "details": reqErr.Message})
 		return
 	}
 
@@ -76,6 +97,7 @@ func (h *UploadHandler) AnalyzeVolcano(c *gin.Context) {
 func (h *UploadHandler) TestVolcanoSimple(c *gin.Context) {
 	startTime := time.Now()
 	log.Printf("收到火山引擎测试请求 - IP: %s", c.ClientIP())
+	h.limitRequestBody(c)
 
 	// 添加原始请求参数调试
 	log.Printf("原始请求参数: %+v", c.Request.Form)
@@ -95,6 +117,8 @@ func (h *UploadHandler) TestVolcanoSimple(c *gin.Context) {
 		StartTime           string   `json:"start_time" form:"start_time"`             // 上班时间
 		EndTime             string   `json:"end_time" form:"end_time"`                 // 下班时间
 		ApplicationDate     string   `json:"application_date" form:"application_date"`
+		StartDate           string   `json:"start_date" form:"start_date"`             // 多日申请开始日期
+		EndDate             string   `json:"end_date" form:"end_date"`                 // 多日申请结束日期
 		Reason              string   `json:"reason" form:"reason"`
 		ImageUrls           []string `json:"image_urls" form:"image_urls[]"`
 		ImageBase64         string   `json:"image_base64" form:"image_base64"` // 新增：base64图片
@@ -108,10 +132,12 @@ func (h *UploadHandler) TestVolcanoSimple(c *gin.Context) {
 		log.Printf("JSON绑定失败，尝试表单绑定: %v", err)
 		if err := c.ShouldBind(&reqData); err != nil {
 			log.Printf("测试请求参数绑定失败: %v", err)
-			c.JSON(http.StatusBadRequest, gin.H{
+			reqErr := asRequestError(err)
+			c.JSON(reqErr.Status, gin.H{
 				"success": false,
 				"message": "请求参数无效",
-				"error":   err.Error(),
+				"code":    reqErr.Code,
+				"error":   reqErr.Message,
 			})
 			return
 		}
@@ -134,6 +160,27 @@ func (h *UploadHandler) TestVolcanoSimple(c *gin.Context) {
 		return
 	}
 
+	// 2.1 校验图片数量与 base64 图片大小（在解码前拦截）
+	imageCount := len(reqData.ImageUrls)
+	if reqData.ImageBase64 != "" {
+		imageCount++
+	}
+	limitErr := h.limits.checkCount(imageCount)
+	if limitErr == nil && reqData.ImageBase64 != "" {
+		limitErr = h.limits.checkBase64("image_base64", reqData.ImageBase64)
+	}
+	if limitErr != nil {
+		reqErr := asRequestError(limitErr)
+		log.Printf("测试请求图片超出限制: %v", reqErr)
+		c.JSON(reqErr.Status, gin.H{
+			"success": false,
+			"message": "图片超出限制",
+			"code":    reqErr.Code,
+			"error":   reqErr.Message,
+		})
+		return
+	}
+
 	// 3. 构建应用数据
 	appData := model.ApplicationData{
 		UserId:          reqData.UserId,
@@ -143,6 +190,8 @@ func (h *UploadHandler) TestVolcanoSimple(c *gin.Context) {
 		StartTime:       reqData.StartTime,       // 上班时间
 		EndTime:         reqData.EndTime,         // 下班时间
 		ApplicationDate: reqData.ApplicationDate,
+		StartDate:       reqData.StartDate,
+		EndDate:         reqData.EndDate,
 		Reason:          reqData.Reason,
 		ImageUrls:       reqData.ImageUrls,
 		AttendanceInfo:  reqData.AttendanceInfo,
@@ -152,8 +201,12 @@ func (h *UploadHandler) TestVolcanoSimple(c *gin.Context) {
 
 	// 如果有base64图片，将其转换为URL格式添加到ImageUrls中
 	if reqData.ImageBase64 != "" {
-		// 为base64图片生成一个临时的data URI
-		dataURI := "data:image/jpeg;base64," + reqData.ImageBase64
+		// 为base64图片生成一个临时的data URI（PDF 以 "%PDF-" 开头，base64 前缀为 "JVBERi0"）
+		mimeType := "image/jpeg"
+		if strings.HasPrefix(reqData.ImageBase64, "JVBERi0") {
+			mimeType = "application/pdf"
+		}
+		dataURI := "data:" + mimeType + ";base64," + reqData.ImageBase64
 		appData.ImageUrls = append(appData.ImageUrls, dataURI)
 		log.Printf("添加base64图片到ImageUrls，当前ImageUrls长度: %d", len(appData.ImageUrls))
 	}
@@ -167,11 +220,19 @@ func (h *UploadHandler) TestVolcanoSimple(c *gin.Context) {
 		log.Printf("警告: 未提供任何时间字段")
 	}
 
-	// 如果既没有传入alias，使用默认值
+	// 如果没有传入alias，优先使用 OA 中的员工姓名，否则使用默认值
 	if reqData.Alias == "" {
-		// 如果既没有OA数据也没有传入alias，使用默认值
-		log.Printf("未提供Alias，使用默认值")
-		appData.Alias = "未知用户"
+		if employee := h.analysisService.LookupEmployee(reqData.UserId); employee != nil && employee.Alias != "" {
+			log.Printf("未提供Alias，使用OA员工姓名: %s", employee.Alias)
+			appData.Alias = employee.Alias
+			if appData.Department == "" {
+				appData.Department = employee.Department
+			}
+		} else {
+			// 如果既没有OA数据也没有传入alias，使用默认值
+			log.Printf("未提供Alias，使用默认值")
+			appData.Alias = "未知用户"
+		}
 	}
 
     // 5. 根据need_image_validation字段调用不同的分析方法
@@ -207,21 +268,21 @@ func (h *UploadHandler) TestVolcanoSimple(c *gin.Context) {
                 }
             }
         }
-        // 回退：若LLM未返回approve，则用规则引擎汇总结果
-        approve := imgApprove
-        if !imgApprove {
-            approve = !result.IsAbnormal
-        }
+        // 以规则引擎的三态结论为准：needs_review 时不自动通过，由 OA 转人工审批
+        approve := result.Verdict == model.VerdictApprove
 
-        log.Printf("火山引擎图片分析完成 (总耗时: %v) - Approve=%v, DateMatch=%v, TimeMatch=%v", totalDuration, approve, dateMatch, timeMatch)
+        log.Printf("火山引擎图片分析完成 (总耗时: %v) - Verdict=%s, LLMApprove=%v, DateMatch=%v, TimeMatch=%v", totalDuration, result.Verdict, imgApprove, dateMatch, timeMatch)
 
         response = gin.H{
-            "valid":      approve,
-            "approve":    approve,
-            "time_match": timeMatch,
-            "date_match": dateMatch,
-            "reason":     result.Reason,
-            "message":    keywords,
+            "valid":          approve,
+            "approve":        approve,
+            "verdict":        result.Verdict,
+            "review_reasons": result.ReviewReasons,
+            "time_validation": result.TimeValidation,
+            "time_match":     timeMatch,
+            "date_match":     dateMatch,
+            "reason":         result.Reason,
+            "message":        keywords,
         }
     } else {
 		// 不需要图片校验，调用纯文字分析方法
@@ -243,17 +304,39 @@ func (h *UploadHandler) TestVolcanoSimple(c *gin.Context) {
         applicationReasonable, _ := resultMap["application_reasonable"].(bool)
         reason, _ := resultMap["reason"].(string)
         suggestion, _ := resultMap["suggestion"].(string)
-        
+        consistency, _ := resultMap["attendance_consistency"].(string)
+
+        // 申请合理但与当日考勤矛盾时转人工复核
+        verdict := model.VerdictReject
+        var reviewReasons []string
+        if applicationReasonable {
+            verdict = model.VerdictApprove
+            if consistency == "矛盾" {
+                verdict = model.VerdictNeedsReview
+                reviewReasons = append(reviewReasons, "申请与当日考勤记录矛盾")
+            }
+        }
+        // 时间校验高风险（非工作日、补卡后仍迟到/早退等）时转人工复核
+        timeValidation := h.analysisService.ValidateTime(appData, h.analysisService.FetchAttendance(appData))
+        if timeValidation != nil && timeValidation.RiskLevel == "high" && verdict == model.VerdictApprove {
+            verdict = model.VerdictNeedsReview
+            reviewReasons = append(reviewReasons, "时间校验高风险: "+timeValidation.Suggestion)
+        }
+        approve := verdict == model.VerdictApprove
+
         // 文字路径无时间对比，time_match与date_match统一返回false
         response = gin.H{
-            "valid":       applicationReasonable,
-            "approve":     applicationReasonable,
-            "time_match":  false,
-            "date_match":  false,
-            "reason":      reason,
-            "message":     suggestion, // 将suggestion作为message返回
-            "request_id":  requestId,
-            "token_usage": tokenUsage,
+            "valid":          approve,
+            "approve":        approve,
+            "verdict":        verdict,
+            "review_reasons": reviewReasons,
+            "time_validation": timeValidation,
+            "time_match":     false,
+            "date_match":     false,
+            "reason":         reason,
+            "message":        suggestion, // 将suggestion作为message返回
+            "request_id":     requestId,
+            "token_usage":    tokenUsage,
         }
 
 		log.Printf("火山引擎文字分析完成 (总耗时: %v) - RequestId: %s", totalDuration, requestId)
@@ -266,8 +349,9 @@ func (h *UploadHandler) TestVolcanoSimple(c *gin.Context) {
 // --- 私有助手: 解析表单数据和可选的图片（支持多图片） ---
 func (h *UploadHandler) bindRequest(c *gin.Context) (model.ApplicationData, []*multipart.FileHeader, error) {
 	var appData model.ApplicationData
+	h.limitRequestBody(c)
 	// 1. 绑定表单数据
-	if err := c.Bind(&appData); err != nil {
+	if err := c.ShouldBind(&appData); err != nil {
 		return model.ApplicationData{}, nil, fmt.Errorf("表单数据无效: %w", err)
 	}
 
@@ -305,6 +389,16 @@ func (h *UploadHandler) bindRequest(c *gin.Context) (model.ApplicationData, []*m
 		return appData, nil, fmt.Errorf("图片文件和图片URL不能同时上传")
 	}
 
+	// 5. 校验数量、大小与像素上限（仅读取图片头，不做完整解码）
+	if err := h.limits.checkCount(len(fileHeaders) + len(appData.ImageUrls)); err != nil {
+		return appData, nil, err
+	}
+	for _, fh := range fileHeaders {
+		if err := h.limits.checkFile(fh); err != nil {
+			return appData, nil, err
+		}
+	}
+
 	log.Printf("解析完成 - 文件数: %d, URL数: %d", len(fileHeaders), len(appData.ImageUrls))
 	return appData, fileHeaders, nil
 }
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime/multipart"
+	"my-ai-app/client"
+	"net/http"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// 上传校验失败时返回的机器可读错误码
+const (
+	ErrCodeTooManyImages   = "too_many_images"   // 图片数量超过上限
+	ErrCodeFileTooLarge    = "file_too_large"    // 单张图片字节数超过上限
+	ErrCodeImageTooLarge   = "image_too_large"   // 单张图片像素数超过上限（疑似解压炸弹）
+	ErrCodeInvalidImage    = "invalid_image"     // 无法识别的图片
+	ErrCodeRequestTooLarge = "request_too_large" // 请求体整体超过上限
+	ErrCodeInvalidRequest  = "invalid_request"   // 其他请求参数错误
+)
+
+// RequestError 带 HTTP 状态码与错误码的请求错误
+type RequestError struct {
+	Status  int    // HTTP 状态码（400 / 413）
+	Code    string // 机器可读错误码
+	Message string // 错误描述
+}
+
+func (e *RequestError) Error() string { return e.Message }
+
+// newRequestError 创建请求错误
+func newRequestError(status int, code string, format string, args ...interface{}) *RequestError {
+	return &RequestError{Status: status, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// asRequestError 将任意错误转换为 RequestError，请求体超限映射为 413
+func asRequestError(err error) *RequestError {
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return newRequestError(http.StatusRequestEntityTooLarge, ErrCodeRequestTooLarge, "请求体超过上限 %d bytes", maxBytesErr.Limit)
+	}
+	return newRequestError(http.StatusBadRequest, ErrCodeInvalidRequest, "%s", err.Error())
+}
+
+// UploadLimits 上传限制
+type UploadLimits struct {
+	MaxFiles     int   // 最大图片数（文件 + URL），<=0 表示不限制
+	MaxFileBytes int64 // 单张图片最大字节数，<=0 表示不限制
+	MaxPixels    int   // 单张图片最大像素数，<=0 表示不限制
+}
+
+// maxRequestBytes 请求体整体上限：所有图片的最大字节数之和，外加 1MB 表单字段余量
+func (l UploadLimits) maxRequestBytes() int64 {
+	if l.MaxFiles <= 0 || l.MaxFileBytes <= 0 {
+		return 0
+	}
+	return int64(l.MaxFiles)*l.MaxFileBytes + 1<<20
+}
+
+// checkCount 校验图片数量
+func (l UploadLimits) checkCount(count int) error {
+	if l.MaxFiles > 0 && count > l.MaxFiles {
+		return newRequestError(http.StatusBadRequest, ErrCodeTooManyImages, "图片数量 %d 超过上限 %d", count, l.MaxFiles)
+	}
+	return nil
+}
+
+// checkFile 在解码前校验上传文件的字节数与像素数
+func (l UploadLimits) checkFile(fh *multipart.FileHeader) error {
+	if l.MaxFileBytes > 0 && fh.Size > l.MaxFileBytes {
+		return newRequestError(http.StatusRequestEntityTooLarge, ErrCodeFileTooLarge,
+			"图片 %s 大小 %d bytes 超过上限 %d bytes", fh.Filename, fh.Size, l.MaxFileBytes)
+	}
+	file, err := fh.Open()
+	if err != nil {
+		return newRequestError(http.StatusBadRequest, ErrCodeInvalidImage, "无法打开图片 %s: %v", fh.Filename, err)
+	}
+	defer file.Close()
+	// PDF 证据的像素上限在逐页提取图片时校验
+	head := make([]byte, 8)
+	n, _ := io.ReadFull(file, head)
+	if client.IsPdf(head[:n]) {
+		return nil
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return newRequestError(http.StatusBadRequest, ErrCodeInvalidImage, "无法读取图片 %s: %v", fh.Filename, err)
+	}
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return newRequestError(http.StatusBadRequest, ErrCodeInvalidImage, "无法识别图片 %s: %v", fh.Filename, err)
+	}
+	return l.checkPixels(fh.Filename, cfg.Width, cfg.Height)
+}
+
+// checkBase64 在解码前校验 base64 图片的字节数与像素数
+func (l UploadLimits) checkBase64(name string, encoded string) error {
+	if l.MaxFileBytes > 0 && int64(base64.StdEncoding.DecodedLen(len(encoded))) > l.MaxFileBytes+2 {
+		return newRequestError(http.StatusRequestEntityTooLarge, ErrCodeFileTooLarge,
+			"图片 %s 大小超过上限 %d bytes", name, l.MaxFileBytes)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return newRequestError(http.StatusBadRequest, ErrCodeInvalidImage, "图片 %s base64 解码失败: %v", name, err)
+	}
+	if client.IsPdf(data) {
+		return nil
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return newRequestError(http.StatusBadRequest, ErrCodeInvalidImage, "无法识别图片 %s: %v", name, err)
+	}
+	return l.checkPixels(name, cfg.Width, cfg.Height)
+}
+
+// checkPixels 校验像素数
+func (l UploadLimits) checkPixels(name string, width, height int) error {
+	if l.MaxPixels > 0 && width*height > l.MaxPixels {
+		return newRequestError(http.StatusRequestEntityTooLarge, ErrCodeImageTooLarge,
+			"图片 %s 尺寸 %dx%d 超过像素上限 %d", name, width, height, l.MaxPixels)
+	}
+	return nil
+}
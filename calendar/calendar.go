@@ -0,0 +1,294 @@
+// Package calendar 中国法定节假日与调休上班日日历
+// 内置各年份数据见 data/<year>.yaml，可通过 HOLIDAY_DATA_DIR 指定目录追加或覆盖年份；未收录的年份按周一至周五为工作日处理
+package calendar
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DayKind 日期属性
+type DayKind string
+
+const (
+	KindWorkday       DayKind = "workday"        // 普通工作日（周一至周五）
+	KindWeekend       DayKind = "weekend"        // 周末
+	KindHoliday       DayKind = "holiday"        // 法定节假日（含连休中的周末）
+	KindMakeupWorkday DayKind = "makeup_workday" // 调休上班日（周末补班）
+	dateLayout                = "2006-01-02"
+)
+
+// Day 某一天的日历信息
+type Day struct {
+	Date  time.Time
+	Kind  DayKind
+	Name  string // 节假日或调休所属节日名称
+	Known bool   // 该年份是否收录了节假日数据
+}
+
+// IsWorkday 是否需要上班
+func (d Day) IsWorkday() bool {
+	return d.Kind == KindWorkday || d.Kind == KindMakeupWorkday
+}
+
+// Label 日期属性的中文说明，如 "法定节假日（国庆节）"
+func (d Day) Label() string {
+	switch d.Kind {
+	case KindHoliday:
+		return fmt.Sprintf("法定节假日（%s）", d.Name)
+	case KindMakeupWorkday:
+		return fmt.Sprintf("调休上班日（%s）", d.Name)
+	case KindWeekend:
+		return "周末休息日"
+	}
+	return "工作日"
+}
+
+// Describe 写入 Prompt / 校验详情的日期说明，如 "2025-10-01 星期三，法定节假日（国庆节、中秋节）"
+func (d Day) Describe() string {
+	s := fmt.Sprintf("%s %s，%s", d.Date.Format(dateLayout), weekdayNames[d.Date.Weekday()], d.Label())
+	if !d.Known {
+		s += fmt.Sprintf("（未收录 %d 年节假日数据，按周一至周五为工作日）", d.Date.Year())
+	}
+	return s
+}
+
+var weekdayNames = [...]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+
+// Calendar 节假日日历
+type Calendar struct {
+	years    map[int]bool
+	holidays map[string]string // 日期 -> 节日名称
+	workdays map[string]string // 调休上班日期 -> 节日名称
+}
+
+// yearFile 单个年份的数据文件
+type yearFile struct {
+	Year     int `yaml:"year"`
+	Holidays []struct {
+		Name string `yaml:"name"`
+		From string `yaml:"from"`
+		To   string `yaml:"to"` // 为空表示仅 from 一天
+	} `yaml:"holidays"`
+	Workdays []struct {
+		Name string `yaml:"name"`
+		Date string `yaml:"date"`
+	} `yaml:"workdays"`
+}
+
+//go:embed data/*.yaml
+var builtinData embed.FS
+
+// New 创建空日历（所有年份按周一至周五为工作日）
+func New() *Calendar {
+	return &Calendar{years: map[int]bool{}, holidays: map[string]string{}, workdays: map[string]string{}}
+}
+
+// Load 加载内置数据，并用 dir 中的 <year>.yaml / <year>.json 追加或覆盖对应年份；dir 为空时仅使用内置数据
+// 返回的日历始终可用，error 仅说明 dir 中有文件加载失败
+func Load(dir string) (*Calendar, error) {
+	c := New()
+	builtin, _ := fs.Glob(builtinData, "data/*.yaml")
+	for _, name := range builtin {
+		data, _ := builtinData.ReadFile(name)
+		if err := c.addYear(data); err != nil {
+			log.Printf("内置节假日数据 %s 无效: %v", name, err)
+		}
+	}
+	if dir == "" {
+		return c, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return c, fmt.Errorf("读取节假日数据目录失败: %w", err)
+	}
+	var errs []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err == nil {
+			err = c.addYear(data)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", entry.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return c, fmt.Errorf("节假日数据加载失败: %s", strings.Join(errs, "; "))
+	}
+	return c, nil
+}
+
+// addYear 加载一个年份的数据，已存在的年份整体替换
+func (c *Calendar) addYear(data []byte) error {
+	var f yearFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("解析失败: %w", err)
+	}
+	if f.Year == 0 {
+		return fmt.Errorf("缺少 year")
+	}
+	holidays := map[string]string{}
+	for _, h := range f.Holidays {
+		from, err := parseYearDate(h.From, f.Year)
+		if err != nil {
+			return err
+		}
+		to := from
+		if h.To != "" {
+			if to, err = parseYearDate(h.To, f.Year); err != nil {
+				return err
+			}
+		}
+		if to.Before(from) {
+			return fmt.Errorf("节假日 %s 的结束日期早于开始日期", h.Name)
+		}
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			holidays[d.Format(dateLayout)] = h.Name
+		}
+	}
+	workdays := map[string]string{}
+	for _, w := range f.Workdays {
+		d, err := parseYearDate(w.Date, f.Year)
+		if err != nil {
+			return err
+		}
+		key := d.Format(dateLayout)
+		if _, ok := holidays[key]; ok {
+			return fmt.Errorf("%s 同时被配置为节假日和调休上班日", key)
+		}
+		workdays[key] = w.Name
+	}
+
+	c.removeYear(f.Year)
+	for k, v := range holidays {
+		c.holidays[k] = v
+	}
+	for k, v := range workdays {
+		c.workdays[k] = v
+	}
+	c.years[f.Year] = true
+	return nil
+}
+
+func (c *Calendar) removeYear(year int) {
+	prefix := fmt.Sprintf("%04d-", year)
+	for k := range c.holidays {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.holidays, k)
+		}
+	}
+	for k := range c.workdays {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.workdays, k)
+		}
+	}
+}
+
+// parseYearDate 解析 yyyy-MM-dd，并要求日期属于数据文件的年份
+func parseYearDate(s string, year int) (time.Time, error) {
+	d, err := time.Parse(dateLayout, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日期[%s]格式无效，应为 yyyy-MM-dd", s)
+	}
+	if d.Year() != year {
+		return time.Time{}, fmt.Errorf("日期[%s]不属于 %d 年", s, year)
+	}
+	return d, nil
+}
+
+// Years 已收录节假日数据的年份
+func (c *Calendar) Years() []int {
+	years := make([]int, 0, len(c.years))
+	for y := range c.years {
+		years = append(years, y)
+	}
+	sort.Ints(years)
+	return years
+}
+
+// Lookup 查询某一天的日历信息，日期取 t 所在时区的日历日期
+func (c *Calendar) Lookup(t time.Time) Day {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	key := date.Format(dateLayout)
+	day := Day{Date: date, Known: c.years[date.Year()], Kind: KindWorkday}
+	if name, ok := c.holidays[key]; ok {
+		day.Kind, day.Name = KindHoliday, name
+	} else if name, ok := c.workdays[key]; ok {
+		day.Kind, day.Name = KindMakeupWorkday, name
+	} else if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		day.Kind = KindWeekend
+	}
+	return day
+}
+
+// Today 查询 loc 时区当天的日历信息（不同办公地的"今天"可能不同）
+func (c *Calendar) Today(loc *time.Location) Day {
+	return c.Lookup(time.Now().In(loc))
+}
+
+// LookupString 按 yyyy-MM-dd 查询
+func (c *Calendar) LookupString(date string) (Day, bool) {
+	t, err := time.Parse(dateLayout, strings.TrimSpace(date))
+	if err != nil {
+		return Day{}, false
+	}
+	return c.Lookup(t), true
+}
+
+// IsWorkday 某一天是否需要上班
+func (c *Calendar) IsWorkday(t time.Time) bool {
+	return c.Lookup(t).IsWorkday()
+}
+
+// WorkingDays 统计日期范围内（含首尾）需要上班的天数
+func (c *Calendar) WorkingDays(start, end time.Time) int {
+	days := 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if c.IsWorkday(d) {
+			days++
+		}
+	}
+	return days
+}
+
+var (
+	defaultMu  sync.RWMutex
+	defaultCal = builtinCalendar()
+)
+
+// builtinCalendar 仅含内置数据的日历
+func builtinCalendar() *Calendar {
+	c, _ := Load("")
+	return c
+}
+
+// Default 全局日历，默认仅含内置数据
+func Default() *Calendar {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultCal
+}
+
+// SetDefault 替换全局日历（启动时按 HOLIDAY_DATA_DIR 加载后调用）
+func SetDefault(c *Calendar) {
+	if c == nil {
+		return
+	}
+	defaultMu.Lock()
+	defaultCal = c
+	defaultMu.Unlock()
+}
@@ -0,0 +1,301 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nfnt/resize"
+)
+
+// 可选的图片增强变换（提升模糊小票、昏暗办公照片的可读性）
+const (
+	EnhanceCrop      = "crop"      // 自动裁掉纯色边框
+	EnhanceGrayscale = "grayscale" // 转灰度（适合单据类文档）
+	EnhanceDenoise   = "denoise"   // 3x3 中值滤波去噪
+	EnhanceContrast  = "contrast"  // 按 1%/99% 分位拉伸对比度
+	EnhanceSharpen   = "sharpen"   // 锐化
+)
+
+// enhanceOrder 变换的固定执行顺序（与配置中的书写顺序无关）
+var enhanceOrder = []string{EnhanceCrop, EnhanceGrayscale, EnhanceDenoise, EnhanceContrast, EnhanceSharpen}
+
+// enhanceMaxSide 增强前先将长边缩放到该尺寸以内，控制滤波耗时
+const enhanceMaxSide = 2000
+
+// ParseEnhanceProfiles 解析按申请类型配置的增强方案
+// 格式："补打卡:crop,contrast,sharpen;病假:crop,grayscale,contrast;*:contrast"，"*" 表示其他类型的默认方案
+func ParseEnhanceProfiles(spec string) map[string][]string {
+	profiles := map[string][]string{}
+	for _, item := range strings.Split(spec, ";") {
+		appType, list, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok || strings.TrimSpace(appType) == "" {
+			continue
+		}
+		var transforms []string
+		for _, t := range strings.Split(list, ",") {
+			t = strings.ToLower(strings.TrimSpace(t))
+			if isKnownEnhance(t) {
+				transforms = append(transforms, t)
+			} else if t != "" {
+				log.Printf("忽略未知的图片增强变换: %s", t)
+			}
+		}
+		if len(transforms) > 0 {
+			profiles[strings.TrimSpace(appType)] = transforms
+		}
+	}
+	return profiles
+}
+
+// EnhanceProfileFor 返回申请类型对应的增强方案，未配置时回退到 "*"
+func EnhanceProfileFor(profiles map[string][]string, appType string) []string {
+	if transforms, ok := profiles[appType]; ok {
+		return transforms
+	}
+	return profiles["*"]
+}
+
+func isKnownEnhance(t string) bool {
+	for _, known := range enhanceOrder {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// EnhanceImageBytes 解码图片、执行增强并按 LLM 输入规格重新编码为 data URI
+// 返回实际生效的变换列表（无需处理的变换不会出现在列表中）
+func EnhanceImageBytes(data []byte, transforms []string, maxPixels int) (string, []string, error) {
+	startTime := time.Now()
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("无法识别图片: %w", err)
+	}
+	if maxPixels > 0 && cfg.Width*cfg.Height > maxPixels {
+		return "", nil, fmt.Errorf("图片尺寸 %dx%d 超过像素上限 %d", cfg.Width, cfg.Height, maxPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("无法解码图片: %w", err)
+	}
+
+	enhanced, applied := EnhanceImage(img, transforms)
+	encoded, mimeType, err := encodeImageForLLM(enhanced)
+	if err != nil {
+		return "", nil, err
+	}
+	log.Printf("图片增强完成 (耗时: %v) - 请求变换: %v, 实际生效: %v", time.Since(startTime), transforms, applied)
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, encoded), applied, nil
+}
+
+// EnhanceImage 按固定顺序执行增强变换，返回处理后的图片与实际生效的变换
+func EnhanceImage(img image.Image, transforms []string) (image.Image, []string) {
+	wanted := map[string]bool{}
+	for _, t := range transforms {
+		wanted[t] = true
+	}
+
+	if b := img.Bounds(); b.Dx() > enhanceMaxSide || b.Dy() > enhanceMaxSide {
+		if b.Dx() >= b.Dy() {
+			img = resize.Resize(enhanceMaxSide, 0, img, resize.Bilinear)
+		} else {
+			img = resize.Resize(0, enhanceMaxSide, img, resize.Bilinear)
+		}
+	}
+	rgba := toRGBA(img)
+
+	var applied []string
+	for _, t := range enhanceOrder {
+		if !wanted[t] {
+			continue
+		}
+		var changed bool
+		switch t {
+		case EnhanceCrop:
+			rgba, changed = autoCrop(rgba)
+		case EnhanceGrayscale:
+			rgba, changed = grayscale(rgba), true
+		case EnhanceDenoise:
+			rgba, changed = medianFilter(rgba), true
+		case EnhanceContrast:
+			rgba, changed = contrastStretch(rgba)
+		case EnhanceSharpen:
+			rgba, changed = sharpen(rgba), true
+		}
+		if changed {
+			applied = append(applied, t)
+		}
+	}
+	return rgba, applied
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Src)
+	return out
+}
+
+func luminance(r, g, b uint8) int {
+	return (299*int(r) + 587*int(g) + 114*int(b)) / 1000
+}
+
+// autoCrop 以四角颜色为边框色，裁掉与其接近的外围行列
+func autoCrop(img *image.RGBA) (*image.RGBA, bool) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	if w < 32 || h < 32 {
+		return img, false
+	}
+	lumAt := func(x, y int) int {
+		i := y*img.Stride + x*4
+		return luminance(img.Pix[i], img.Pix[i+1], img.Pix[i+2])
+	}
+	corners := []int{lumAt(0, 0), lumAt(w-1, 0), lumAt(0, h-1), lumAt(w-1, h-1)}
+	sort.Ints(corners)
+	border := (corners[1] + corners[2]) / 2
+	const tolerance = 18
+
+	// 行/列中偏离边框色的像素超过 1% 即视为内容
+	isContentRow := func(y int) bool {
+		count := 0
+		for x := 0; x < w; x++ {
+			if d := lumAt(x, y) - border; d > tolerance || d < -tolerance {
+				count++
+			}
+		}
+		return count*100 > w
+	}
+	isContentCol := func(x int) bool {
+		count := 0
+		for y := 0; y < h; y++ {
+			if d := lumAt(x, y) - border; d > tolerance || d < -tolerance {
+				count++
+			}
+		}
+		return count*100 > h
+	}
+
+	top, bottom, left, right := 0, h-1, 0, w-1
+	for top < bottom && !isContentRow(top) {
+		top++
+	}
+	for bottom > top && !isContentRow(bottom) {
+		bottom--
+	}
+	for left < right && !isContentCol(left) {
+		left++
+	}
+	for right > left && !isContentCol(right) {
+		right--
+	}
+
+	cw, ch := right-left+1, bottom-top+1
+	// 裁剪量过小不处理；裁剪后过小说明整图接近纯色，保持原图
+	if (cw*ch)*100 > w*h*98 || cw*2 < w/2 || ch*2 < h/2 {
+		return img, false
+	}
+	return toRGBA(img.SubImage(image.Rect(left, top, right+1, bottom+1))), true
+}
+
+// grayscale 转为灰度（保留 RGBA 存储以便后续变换统一处理）
+func grayscale(img *image.RGBA) *image.RGBA {
+	out := image.NewRGBA(img.Rect)
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		l := uint8(luminance(img.Pix[i], img.Pix[i+1], img.Pix[i+2]))
+		out.Pix[i], out.Pix[i+1], out.Pix[i+2], out.Pix[i+3] = l, l, l, 255
+	}
+	return out
+}
+
+// contrastStretch 以亮度 1%/99% 分位为端点做线性拉伸，动态范围已足够时不处理
+func contrastStretch(img *image.RGBA) (*image.RGBA, bool) {
+	var hist [256]int
+	total := 0
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		hist[luminance(img.Pix[i], img.Pix[i+1], img.Pix[i+2])]++
+		total++
+	}
+	if total == 0 {
+		return img, false
+	}
+	low, high := 0, 255
+	for acc := 0; low < 255; low++ {
+		if acc += hist[low]; acc*100 >= total {
+			break
+		}
+	}
+	for acc := 0; high > 0; high-- {
+		if acc += hist[high]; acc*100 >= total {
+			break
+		}
+	}
+	if high-low >= 230 || high <= low {
+		return img, false
+	}
+
+	var lut [256]uint8
+	for v := 0; v < 256; v++ {
+		scaled := (v - low) * 255 / (high - low)
+		lut[v] = uint8(min(255, max(0, scaled)))
+	}
+	out := image.NewRGBA(img.Rect)
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		out.Pix[i], out.Pix[i+1], out.Pix[i+2], out.Pix[i+3] = lut[img.Pix[i]], lut[img.Pix[i+1]], lut[img.Pix[i+2]], 255
+	}
+	return out, true
+}
+
+// medianFilter 3x3 中值滤波，去除噪点同时保留文字边缘
+func medianFilter(img *image.RGBA) *image.RGBA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	out := image.NewRGBA(img.Rect)
+	copy(out.Pix, img.Pix)
+	var window [9]uint8
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			for c := 0; c < 3; c++ {
+				n := 0
+				for dy := -1; dy <= 1; dy++ {
+					row := (y+dy)*img.Stride + c
+					for dx := -1; dx <= 1; dx++ {
+						window[n] = img.Pix[row+(x+dx)*4]
+						n++
+					}
+				}
+				// 9 个元素的插入排序
+				for i := 1; i < 9; i++ {
+					for j := i; j > 0 && window[j] < window[j-1]; j-- {
+						window[j], window[j-1] = window[j-1], window[j]
+					}
+				}
+				out.Pix[y*out.Stride+x*4+c] = window[4]
+			}
+		}
+	}
+	return out
+}
+
+// sharpen 使用拉普拉斯核锐化：中心 5，上下左右 -1
+func sharpen(img *image.RGBA) *image.RGBA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	out := image.NewRGBA(img.Rect)
+	copy(out.Pix, img.Pix)
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*img.Stride + x*4
+			for c := 0; c < 3; c++ {
+				v := 5*int(img.Pix[i+c]) - int(img.Pix[i+c-4]) - int(img.Pix[i+c+4]) -
+					int(img.Pix[i+c-img.Stride]) - int(img.Pix[i+c+img.Stride])
+				out.Pix[i+c] = uint8(min(255, max(0, v)))
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrImageTooLarge 图片字节数或像素数超过上限
+var ErrImageTooLarge = errors.New("图片超过大小限制")
+
+// imageFetchClient 用于下载 URL 图片原始字节（取证、哈希等非 LLM 场景）
+var imageFetchClient = &http.Client{Timeout: 15 * time.Second}
+
+// LoadImageBytes 读取图片原始字节
+// 支持 fileHeader（直接上传）、data URI（base64）以及 http(s) URL，maxBytes<=0 表示不限制大小
+func LoadImageBytes(fileHeader *multipart.FileHeader, imageURL string, maxBytes int64) ([]byte, error) {
+	if fileHeader != nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, fmt.Errorf("无法打开文件: %w", err)
+		}
+		defer file.Close()
+		return readLimited(file, maxBytes)
+	}
+
+	if imageURL == "" {
+		return nil, fmt.Errorf("没有提供图片文件或图片URL")
+	}
+
+	// data URI：data:image/jpeg;base64,xxxx
+	if strings.HasPrefix(imageURL, "data:") {
+		comma := strings.Index(imageURL, ",")
+		if comma < 0 || !strings.Contains(imageURL[:comma], ";base64") {
+			return nil, fmt.Errorf("不支持的 data URI 格式")
+		}
+		data, err := base64.StdEncoding.DecodeString(imageURL[comma+1:])
+		if err != nil {
+			return nil, fmt.Errorf("base64 解码失败: %w", err)
+		}
+		if maxBytes > 0 && int64(len(data)) > maxBytes {
+			return nil, fmt.Errorf("%w: %d bytes 超过 %d bytes", ErrImageTooLarge, len(data), maxBytes)
+		}
+		return data, nil
+	}
+
+	resp, err := imageFetchClient.Get(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("下载图片失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载图片失败，状态码: %d", resp.StatusCode)
+	}
+	if maxBytes > 0 && resp.ContentLength > maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes 超过 %d bytes", ErrImageTooLarge, resp.ContentLength, maxBytes)
+	}
+	return readLimited(resp.Body, maxBytes)
+}
+
+// CheckImagePixels 解码前按图片头校验像素数，maxPixels<=0 表示不限制；无法识别的格式（如 PDF）不在此校验
+func CheckImagePixels(data []byte, maxPixels int) error {
+	if maxPixels <= 0 {
+		return nil
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return fmt.Errorf("%w: 尺寸 %dx%d 超过像素上限 %d", ErrImageTooLarge, cfg.Width, cfg.Height, maxPixels)
+	}
+	return nil
+}
+
+// readLimited 读取全部内容，超过 maxBytes 时返回错误
+func readLimited(r io.Reader, maxBytes int64) ([]byte, error) {
+	if maxBytes <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取图片失败: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w: 超过 %d bytes", ErrImageTooLarge, maxBytes)
+	}
+	return data, nil
+}
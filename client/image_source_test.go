@@ -0,0 +1,48 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func pngBytes(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoadImageBytesURLLimit(t *testing.T) {
+	body := bytes.Repeat([]byte{0xFF}, 4096)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	if _, err := LoadImageBytes(nil, srv.URL, 1024); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("超过上限的 URL 图片应返回 ErrImageTooLarge, got %v", err)
+	}
+	if data, err := LoadImageBytes(nil, srv.URL, 8192); err != nil || len(data) != len(body) {
+		t.Errorf("未超过上限的 URL 图片读取失败: %v", err)
+	}
+}
+
+func TestCheckImagePixels(t *testing.T) {
+	data := pngBytes(t, 200, 100)
+	if err := CheckImagePixels(data, 20_000); err != nil {
+		t.Errorf("20000 像素不应超限: %v", err)
+	}
+	if err := CheckImagePixels(data, 19_999); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("应超过像素上限, got %v", err)
+	}
+	if err := CheckImagePixels([]byte("%PDF-1.4"), 1); err != nil {
+		t.Errorf("非图片格式不在此校验: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"my-ai-app/model"
+	"my-ai-app/timeparse"
+	"strings"
+	"time"
+)
+
+// normalizeExtractedData 将 LLM 输出的日期与时间规整为规则引擎使用的写法
+// （"下午6点32" -> "18:32"、"昨天 18:32" -> "2025-10-14 18:32"、"10月15日" -> "2025-10-15"），相对日期按申请日期换算，无法识别的保持原样
+func normalizeExtractedData(d *model.ExtractedData, applicationDate string) {
+	var ref time.Time
+	if year, month, day, ok := timeparse.DateParts(applicationDate); ok && year > 0 {
+		ref = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+	for _, field := range []*string{&d.RequestDate, &d.EvidenceStartDate, &d.EvidenceEndDate} {
+		*field = normalizeDateField(*field, ref)
+	}
+	d.RequestTime = normalizeTimeField(d.RequestTime, ref)
+	d.TimeFromContent = normalizeTimeField(d.TimeFromContent, ref)
+	for i, t := range d.CandidateTimes {
+		d.CandidateTimes[i] = normalizeTimeField(t, ref)
+	}
+}
+
+func normalizeDateField(s string, ref time.Time) string {
+	if strings.TrimSpace(s) == "" || s == "未知" {
+		return s
+	}
+	if out, ok := timeparse.NormalizeDate(s, ref); ok {
+		return out
+	}
+	return s
+}
+
+// normalizeTimeField 逐个规整逗号/顿号分隔的时间；含多个时间的片段（如 "09:00-18:00"）保持原样
+func normalizeTimeField(s string, ref time.Time) string {
+	if strings.TrimSpace(s) == "" || s == "未知" {
+		return s
+	}
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '，' || r == '、' || r == ';' })
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		parts[i] = part
+		if len(timeparse.FindClocks(part)) > 1 {
+			continue
+		}
+		if out, ok := timeparse.NormalizeTime(part, ref); ok {
+			parts[i] = out
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"log"
 	"mime/multipart"
+	"my-ai-app/calendar"
 
 	_ "image/gif"
 	_ "image/png"
@@ -24,6 +25,15 @@ import (
 	"github.com/nfnt/resize"
 )
 
+// 模型与 Prompt 版本（参与判定缓存键，修改 Prompt 时需同步递增版本号）
+const (
+	VolcanoVisionModel   = "doubao-seed-1-6-lite-251015"
+	VolcanoTextModel     = "doubao-seed-1-6-vision-250815"
+	QwenVisionModel      = "qwen3-vl-plus"
+	VolcanoPromptVersion = "volcano-v5"
+	QwenPromptVersion    = "qwen-v5"
+)
+
 // ... (VisionMessage, ContentPart, ChatMessageImageURL, LlmResponse 结构体保持不变) ...
 type VisionMessage struct {
 	Role    string        `json:"role"`
@@ -64,6 +74,11 @@ func processImageStream(imageStream io.Reader) (string, string, error) {
 		return "", "", fmt.Errorf("无法解码图片: %w", err)
 	}
 	log.Printf("图片原始格式: %s, 原始尺寸: %dx%d", originalFormat, img.Bounds().Dx(), img.Bounds().Dy())
+	return encodeImageForLLM(img)
+}
+
+// encodeImageForLLM 缩放到 1000x1000 以内并重编码为 JPEG，返回 base64 与 MIME 类型
+func encodeImageForLLM(img image.Image) (string, string, error) {
 	const maxWidth uint = 1000
 	const maxHeight uint = 1000
 	if img.Bounds().Dx() > int(maxWidth) || img.Bounds().Dy() > int(maxHeight) {
@@ -222,9 +237,12 @@ func buildExtractorPrompt(appName string, appType string, appDate string, appSta
 物理在司证明: 饭堂/内部消费小票, 门禁刷卡记录, 包含公司环境的带时间戳照片, 办公楼下快递签收记录等。
 数字在司证明: 电脑系统日志 (如 事件查看器 (Event Viewer), 开关机记录), 内部OA/ERP/Git/Jira等系统操作截图, VPN登录记录, 有上下文的(显示了工作内容)且带时间戳的工作聊天记录。
 AI 指引: AI应优先采信这些类别的证据，只要它们能清晰展示时间戳并与员工的工作相关联（如系统日志能证明电脑在运行），就应视为有效（true），而不是因其无法同时满足所有绑定条件（如“事件查看器”无法直接绑定“员工”）而拒绝。
+若为 "婚假"/"产假"/"出差"/"外出"/"加班" 等: 证据是否为该类申请对应的材料（如结婚证、出生证明、机票/车票/酒店订单、加班审批记录）？
 若为 "其他": 证据是否能支持申请人提出的具体事由？
-5. 提取图片中的关键字摘要（≤60字，不要重复时间）。
+5. 提取图片中的关键字摘要（≤60字，不要重复时间），并在 proof_type 中写出证明材料的类型（如 诊断证明、结婚证、出生证明、机票、火车票、酒店订单、加班审批单、聊天记录、打卡记录，无法判断写“其他”）。
 6. 判断图片是否为聊天记录。
+   同时如实提取图片中的日期与时间（供系统复核，不要用申请的日期/时间代填）：request_date 为最接近申请日期的图片日期（yyyy-MM-dd，图片只有月日时写 MM-dd，无则空）；request_time 为最接近申请时间的图片时间（HH:mm，无则空）；candidate_times 为图片中出现的所有时间点（HH:mm，最多5个）。
+   申请日期为 "开始~结束" 的多日申请时，另提取证明材料覆盖的日期范围：evidence_start_date / evidence_end_date（yyyy-MM-dd，如病假条建议休息的起止日期、行程单往返日期；无则空）。
 7. 分析并给出符合 / 不符合的原因，需综合考虑图片内容、时间、考勤数据等多方面因素导致申请无效的情况。
 8. 给出AI的建议，即是否建议通过该申请。
 
@@ -234,7 +252,13 @@ AI 指引: AI应优先采信这些类别的证据，只要它们能清晰展示
   "date_match": true/false,
   "time_match": true/false,
   "type_match": true/false,
+  "request_date": "",
+  "request_time": "",
+  "candidate_times": [],
+  "evidence_start_date": "",
+  "evidence_end_date": "",
   "keywords": "",
+  "proof_type": "",
   "is_chat_record": true/false,
   "reason": "",
   "approve": true/false
@@ -255,7 +279,7 @@ func buildPromptByType(employeeName string, appType string, applicationDate stri
 - 证明图片: {{IMAGE_PROOF}}
 - 日期: {{APPLICATION_DATE}}
 - 时间: {{APPLICATION_TIME}}
-- 类型: {{APPLICATION_TYPE}}（补打卡/病假）
+- 类型: {{APPLICATION_TYPE}}（补打卡/病假/婚假/产假/出差/外出/加班等）
 - 员工姓名: {{EMPLOYEE_NAME}}
 
 ## 判断标准:
@@ -276,12 +300,22 @@ func buildPromptByType(employeeName string, appType string, applicationDate stri
     - 图片需为病历单、处方单、诊断证明等能证明在医院就医的材料。
     - 图片中能识别出 {{EMPLOYEE_NAME}} 是患者/看诊人，且日期符合 {{APPLICATION_DATE}}。
 
+### 其他类型（婚假/产假/出差/外出/加班等）
+1. 图片需为该类申请对应的证明材料：婚假为结婚证；产假为出生证明、诊断证明等；出差/外出为机票、车票、酒店订单或审批记录；加班为加班审批或工作记录。
+2. 图片日期需与 {{APPLICATION_DATE}} 相符（婚假、产假材料日期可早于申请日期），time_match 始终为 false。
+
 ## 输出格式要求:
 请严格输出以下 JSON，不得添加任何解释、推理、过程描述或额外内容；如需要返回时间，必须是规范化后的 HH:mm：
 {
     "date_match": true/false,
     "time_match": true/false,
+    "request_date": "",
+    "request_time": "",
+    "candidate_times": [],
+    "evidence_start_date": "",
+    "evidence_end_date": "",
     "keywords": "",
+    "proof_type": "",
     "is_chat_record": false,
     "reason": "",
     "approve": true/false,
@@ -291,7 +325,12 @@ func buildPromptByType(employeeName string, appType string, applicationDate stri
 ### 字段说明:
 - date_match: 日期是否匹配。
 - time_match: 时间是否匹配（补打卡类型有效，病假类型始终为 false）。
+- request_date: 图片中最接近申请日期的日期（yyyy-MM-dd，图片只有月日时写 MM-dd，无则空；不要用申请日期代填）。
+- request_time: 图片中最接近申请时间的时间（HH:mm，无则空；不要用申请时间代填）。
+- candidate_times: 图片中出现的所有时间点（HH:mm，最多5个）。
+- evidence_start_date / evidence_end_date: 申请日期为 "开始~结束" 的多日申请时，证明材料覆盖的起止日期（yyyy-MM-dd，如病假条建议休息的起止日期、行程单往返日期；无则空）。
 - keywords: 识别到的关键信息（如图片中的日期、时间、员工姓名等）。
+- proof_type: 证明材料类型（如 诊断证明、结婚证、出生证明、机票、火车票、酒店订单、加班审批单、聊天记录、打卡记录，无法判断写“其他”）。
 - is_chat_record: 是否为聊天记录（针对补打卡的判断）。
 - reason: 只写最终结论，不写思考过程，不超过 60 字，例如：
   - 示例："时间不符，图片无相关记录。"
@@ -312,10 +351,12 @@ func buildNoImagePrompt(appName string, appType string, appDate string, appTime
 - 申请日期：%s
 - 申请时间：%s
 - 当日考勤时间点（HH:mm 列表，上下班/打卡记录）：%s
+- 日历：%s
 
 要求：
 1) 先判断该日期是工作日还是节假日：
-   - 如无法联网获取法定节假日，按周一~周五视为“工作日”，周六/周日视为“节假日”，并在 reason 中注明依据。
+   - 以输入中的“日历”为准（已包含法定节假日与调休上班日，调休上班日视为“工作日”），不要自行推断；
+   - 日历注明未收录节假日数据时，按周一~周五视为“工作日”，周六/周日视为“节假日”，并在 reason 中注明依据。
 2) 基于当日属性与“申请类型”评估“是否合理”：
    - 请假类（事假/病假/年假/调休等）→ 工作日更合理；
    - 节假日加班/加班调休 → 节假日更合理；
@@ -335,7 +376,16 @@ func buildNoImagePrompt(appName string, appType string, appDate string, appTime
   "reason": "",
   "suggestion": ""
 }
-`, appType, appName, appDate, appTime, attendanceText)
+`, appType, appName, appDate, appTime, attendanceText, describeDay(appDate))
+}
+
+// describeDay 申请日期的日历说明（法定节假日/调休上班日/周末/工作日）
+func describeDay(appDate string) string {
+	day, ok := calendar.Default().LookupString(appDate)
+	if !ok {
+		return "申请日期无法解析，按周一~周五视为工作日"
+	}
+	return day.Describe()
 }
 
 func displayAppTime(appStart, appEnd string) string {
@@ -370,10 +420,12 @@ func buildCheckByNoImagePrompt(appType string, appName string, appDate string, a
 - 申请日期：%s
 - 申请时间：%s
 - 当日考勤时间点（HH:mm 列表，上下班/打卡记录）：%s
+- 日历：%s
 
 要求：
 1) 先判断该日期是工作日还是节假日：
-   - 如无法联网获取法定节假日，按周一~周五视为“工作日”，周六/周日视为“节假日”，并在 reason 中注明依据。
+   - 以输入中的“日历”为准（已包含法定节假日与调休上班日，调休上班日视为“工作日”），不要自行推断；
+   - 日历注明未收录节假日数据时，按周一~周五视为“工作日”，周六/周日视为“节假日”，并在 reason 中注明依据。
 2) 基于当日属性与“申请类型”评估“是否合理”：
    - 请假类（事假/病假/年假/调休等）→ 工作日更合理；
    - 节假日加班/加班调休 → 节假日更合理；
@@ -392,5 +444,5 @@ func buildCheckByNoImagePrompt(appType string, appName string, appDate string, a
   "reason": "",
   "suggestion": ""
 }
-`, appType, appName, appDate, appTime, attendanceText)
+`, appType, appName, appDate, appTime, attendanceText, describeDay(appDate))
 }
\ No newline at end of file
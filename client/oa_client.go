@@ -1,73 +1,223 @@
 package client
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"my-ai-app/calendar"
+	"my-ai-app/model"
+	"my-ai-app/schedule"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
-// OaClient 负责与 OA 系统的 API 通信
-type OaClient struct {
-	baseURL    string
-	httpClient *http.Client
+// OaBackend OA 系统接口：员工基准数据与当天考勤
+// 内置 REST 实现（RestOaClient）与本地模拟实现（MockOaClient），由 OA_BACKEND 选择
+type OaBackend interface {
+	GetEmployeeData(employeeID string) (*EmployeeData, error)
+	GetAttendanceData(employeeID string, workDate string) (*model.AttendanceData, error)
 }
 
+// ErrOaNotFound OA 中不存在该员工或当天考勤
+var ErrOaNotFound = errors.New("OA 未找到对应数据")
+
 // EmployeeData (OA系统返回的员工基准数据)
 type EmployeeData struct {
-	UserId string `json:"user_id"`
-	Alias  string `json:"alias"`
+	UserId     string `json:"user_id"`
+	Alias      string `json:"alias"`
+	Department string `json:"department"`
+}
+
+// NewOaBackend 按配置创建 OA 实现，关闭时返回 nil
+// backend 为空时：配置了 baseURL 则使用 REST 接口，否则关闭
+func NewOaBackend(backend, baseURL, token string, timeout time.Duration, maxRetries int) OaBackend {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case "mock":
+		log.Printf("OA 使用本地模拟数据")
+		return NewMockOaClient()
+	case "none", "off":
+		return nil
+	case "", "rest":
+		if baseURL == "" {
+			return nil
+		}
+		log.Printf("OA 使用 REST 接口: %s", baseURL)
+		return NewRestOaClient(baseURL, token, timeout, maxRetries)
+	default:
+		log.Printf("未知的 OA 实现 %q，OA 已关闭", backend)
+		return nil
+	}
+}
+
+// RestOaClient 通过 REST 接口访问 OA 系统
+// - GET {baseURL}/employees/{user_id}
+// - GET {baseURL}/attendance?user_id=&date=yyyy-MM-dd
+// 请求携带 Authorization: Bearer {token}；响应体可为数据本身，也可包裹在 {"code":0,"data":{...}} 中
+// 网络错误、429 与 5xx 按指数退避重试，404 返回 ErrOaNotFound
+type RestOaClient struct {
+	baseURL    string
+	token      string
+	maxRetries int
+	httpClient *http.Client
+}
+
+// NewRestOaClient 创建 REST OA 客户端
+func NewRestOaClient(baseURL, token string, timeout time.Duration, maxRetries int) *RestOaClient {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return &RestOaClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		maxRetries: maxRetries,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetEmployeeData 从 OA 系统获取员工的基准数据
+func (c *RestOaClient) GetEmployeeData(employeeID string) (*EmployeeData, error) {
+	var data EmployeeData
+	if err := c.get("/employees/"+url.PathEscape(employeeID), nil, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// GetAttendanceData 从 OA 系统获取员工当天的考勤数据
+func (c *RestOaClient) GetAttendanceData(employeeID string, workDate string) (*model.AttendanceData, error) {
+	startTime := time.Now()
+	var data model.AttendanceData
+	query := url.Values{"user_id": {employeeID}, "date": {workDate}}
+	if err := c.get("/attendance", query, &data); err != nil {
+		return nil, err
+	}
+	log.Printf("OA 考勤数据获取完成 (耗时: %v) - EmployeeID: %s, WorkDate: %s, 打卡: %s~%s",
+		time.Since(startTime), employeeID, workDate, data.ClockInTime, data.ClockOutTime)
+	return &data, nil
 }
 
-// AttendanceData OA系统返回的考勤数据
-type AttendanceData struct {
-	UserId         string `json:"user_id"`         // 员工ID
-	WorkDate       string `json:"work_date"`       // 工作日期 YYYY-MM-DD
-	WorkStartTime  string `json:"work_start_time"` // 上班时间 HH:mm
-	WorkEndTime    string `json:"work_end_time"`   // 下班时间 HH:mm
-	IsWorkDay      bool   `json:"is_work_day"`     // 是否工作日
-	AttendanceType string `json:"attendance_type"` // 考勤类型: normal, late, absent
+// oaEnvelope 兼容 {"code":0,"message":"","data":{...}} 格式的响应
+type oaEnvelope struct {
+	Code    *int            `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
 }
 
-func NewOaClient(baseURL string) *OaClient {
-	return &OaClient{
-		baseURL:    baseURL,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+// get 发送 GET 请求并解析响应，失败时按指数退避重试
+func (c *RestOaClient) get(path string, query url.Values, out interface{}) error {
+	reqURL := c.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
 	}
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<(attempt-1)) * 200 * time.Millisecond
+			log.Printf("OA 请求第 %d 次重试（%v 后）: %v", attempt, backoff, lastErr)
+			time.Sleep(backoff)
+		}
+		body, retryable, err := c.do(reqURL)
+		if err == nil {
+			return decodeOaResponse(body, out)
+		}
+		lastErr = err
+		if !retryable {
+			break
+		}
+	}
+	return lastErr
+}
+
+// do 执行一次请求，返回响应体及失败时是否可重试
+func (c *RestOaClient) do(reqURL string) ([]byte, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("构建 OA 请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("发送 OA 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("读取 OA 响应失败: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return body, false, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, false, ErrOaNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, true, fmt.Errorf("OA 请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	default:
+		return nil, false, fmt.Errorf("OA 请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+}
+
+// decodeOaResponse 解析响应体，兼容数据本身与 {"code","data"} 包裹两种格式
+func decodeOaResponse(body []byte, out interface{}) error {
+	var envelope oaEnvelope
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Code != nil {
+		if *envelope.Code != 0 {
+			return fmt.Errorf("OA 返回错误 (code=%d): %s", *envelope.Code, envelope.Message)
+		}
+		if len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+			return ErrOaNotFound
+		}
+		body = envelope.Data
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("解析 OA 响应失败: %w", err)
+	}
+	return nil
 }
 
-// GetEmployeeData 从 OA 系统获取员工的基准数据 todo 调整员工信息的判断
-func (c *OaClient) GetEmployeeData(employeeID string) (*EmployeeData, error) {
-	// 临时返回模拟数据（开发环境）
+// MockOaClient 本地模拟实现：员工信息为占位数据，考勤为当天班次且无打卡记录（未接入 OA 的本地开发/联调使用）
+type MockOaClient struct{}
+
+// NewMockOaClient 创建模拟 OA 客户端
+func NewMockOaClient() *MockOaClient { return &MockOaClient{} }
+
+// GetEmployeeData 返回占位的员工数据
+func (c *MockOaClient) GetEmployeeData(employeeID string) (*EmployeeData, error) {
 	return &EmployeeData{
 		UserId: employeeID,
 		Alias:  "测试员工",
 	}, nil
 }
 
-// GetAttendanceData 从 OA 系统获取员工的考勤数据
-func (c *OaClient) GetAttendanceData(employeeID string, workDate string) (*AttendanceData, error) {
+// GetAttendanceData 返回模拟的考勤数据
+func (c *MockOaClient) GetAttendanceData(employeeID string, workDate string) (*model.AttendanceData, error) {
 	log.Printf("开始获取员工考勤数据 - EmployeeID: %s, WorkDate: %s", employeeID, workDate)
-	// todo 调取接口？ 或者是在请求的时候一起带过来
-	// 临时返回模拟数据（开发环境）
-	// 根据日期判断是否为工作日
 	parsedDate, err := time.Parse("2006-01-02", workDate)
 	if err != nil {
 		return nil, fmt.Errorf("日期格式错误: %w", err)
 	}
 
-	// 简单的工作日判断：周一到周五
-	weekday := parsedDate.Weekday()
-	isWorkDay := weekday >= time.Monday && weekday <= time.Friday
+	// 按节假日日历判断工作日（含法定节假日与调休上班日）
+	isWorkDay := calendar.Default().IsWorkday(parsedDate)
+
+	// 上下班时间取员工当天的班次（未配置排班时为标准班 09:00~18:00）
+	shift := schedule.Default().ShiftFor(employeeID, "", parsedDate)
 
 	// 模拟考勤数据
-	attendanceData := &AttendanceData{
-		UserId:         employeeID,
-		WorkDate:       workDate,
-		WorkStartTime:  "09:00",
-		WorkEndTime:    "18:00",
-		IsWorkDay:      isWorkDay,
-		AttendanceType: "normal",
+	attendanceData := &model.AttendanceData{
+		UserId:          employeeID,
+		WorkDate:        workDate,
+		IsWorkDay:       isWorkDay,
+		StandardInTime:  shift.Start,
+		StandardOutTime: shift.End,
 	}
 
 	log.Printf("返回模拟考勤数据: %+v", attendanceData)
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"my-ai-app/timeparse"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OcrEngine OCR 识别接口
+// 在 LLM 之前运行，预先提取图片中的日期与时间，既作为 Prompt 提示，也供规则引擎直接校验
+type OcrEngine interface {
+	Recognize(imageData []byte) (*OcrResult, error)
+}
+
+// OcrResult OCR 识别结果
+type OcrResult struct {
+	Lines []string // 识别出的文本行
+	Dates []string // 提取的日期（yyyy-MM-dd，无年份时为 MM-dd）
+	Times []string // 提取的时间（HH:mm）
+}
+
+// NewOcrEngine 按配置创建 OCR 实现，关闭时返回 nil
+// engine 为空时：配置了 url 则使用 HTTP 服务，否则关闭
+func NewOcrEngine(engine string, url string, timeout time.Duration) OcrEngine {
+	switch strings.ToLower(strings.TrimSpace(engine)) {
+	case "stub":
+		log.Printf("OCR 使用本地桩实现")
+		return &StubOcrEngine{}
+	case "none", "off":
+		return nil
+	case "", "http":
+		if url == "" {
+			return nil
+		}
+		log.Printf("OCR 使用 HTTP 服务: %s", url)
+		return NewHttpOcrClient(url, timeout)
+	default:
+		log.Printf("未知的 OCR 实现 %q，OCR 已关闭", engine)
+		return nil
+	}
+}
+
+// HttpOcrClient 通过 HTTP 调用 OCR 服务（如 PaddleOCR Serving）
+// 请求体：{"images": ["<base64>"]}
+// 响应体兼容 {"lines":[{"text":""}]} 与 PaddleHub 的 {"results":[[{"text":""}]]} 两种格式
+type HttpOcrClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewHttpOcrClient 创建 HTTP OCR 客户端
+func NewHttpOcrClient(url string, timeout time.Duration) *HttpOcrClient {
+	return &HttpOcrClient{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type ocrTextLine struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+}
+
+type ocrResponse struct {
+	Lines   []ocrTextLine   `json:"lines"`
+	Results [][]ocrTextLine `json:"results"`
+}
+
+// Recognize 调用 OCR 服务识别图片文字
+func (c *HttpOcrClient) Recognize(imageData []byte) (*OcrResult, error) {
+	startTime := time.Now()
+	reqBytes, err := json.Marshal(map[string][]string{
+		"images": {base64.StdEncoding.EncodeToString(imageData)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("构建 OCR 请求体失败: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(c.url, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("发送 OCR 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取 OCR 响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCR 服务请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
+	}
+
+	var parsed ocrResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("解析 OCR 响应失败: %w", err)
+	}
+	lines := parsed.Lines
+	for _, group := range parsed.Results {
+		lines = append(lines, group...)
+	}
+	texts := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if t := strings.TrimSpace(l.Text); t != "" {
+			texts = append(texts, t)
+		}
+	}
+
+	result := NewOcrResult(texts)
+	log.Printf("OCR 识别完成 (耗时: %v) - 行数: %d, 日期: %v, 时间: %v",
+		time.Since(startTime), len(texts), result.Dates, result.Times)
+	return result, nil
+}
+
+// StubOcrEngine 本地桩实现：不做识别，返回预置文本（未配置 OCR 服务的本地开发/联调使用）
+type StubOcrEngine struct {
+	Lines []string
+}
+
+// Recognize 返回预置文本中提取的日期与时间
+func (e *StubOcrEngine) Recognize(imageData []byte) (*OcrResult, error) {
+	return NewOcrResult(e.Lines), nil
+}
+
+var (
+	ocrFullDateRegex = regexp.MustCompile(`(\d{4})\s*[-/.年]\s*(\d{1,2})\s*[-/.月]\s*(\d{1,2})`)
+	ocrMonthDayRegex = regexp.MustCompile(`(\d{1,2})\s*月\s*(\d{1,2})\s*日`)
+)
+
+// NewOcrResult 从文本行中提取日期与时间（去重并保持出现顺序）
+func NewOcrResult(lines []string) *OcrResult {
+	result := &OcrResult{Lines: lines}
+	seenDates := map[string]bool{}
+	seenTimes := map[string]bool{}
+	addDate := func(d string) {
+		if !seenDates[d] {
+			seenDates[d] = true
+			result.Dates = append(result.Dates, d)
+		}
+	}
+
+	for _, line := range lines {
+		rest := line
+		for _, m := range ocrFullDateRegex.FindAllStringSubmatch(line, -1) {
+			month, _ := strconv.Atoi(m[2])
+			day, _ := strconv.Atoi(m[3])
+			if month >= 1 && month <= 12 && day >= 1 && day <= 31 {
+				addDate(fmt.Sprintf("%s-%02d-%02d", m[1], month, day))
+			}
+			rest = strings.Replace(rest, m[0], " ", 1)
+		}
+		for _, m := range ocrMonthDayRegex.FindAllStringSubmatch(rest, -1) {
+			month, _ := strconv.Atoi(m[1])
+			day, _ := strconv.Atoi(m[2])
+			if month >= 1 && month <= 12 && day >= 1 && day <= 31 {
+				addDate(fmt.Sprintf("%02d-%02d", month, day))
+			}
+		}
+		for _, t := range timeparse.FindClocks(line) {
+			if !seenTimes[t] {
+				seenTimes[t] = true
+				result.Times = append(result.Times, t)
+			}
+		}
+	}
+	return result
+}
+
+// BuildOcrHint 将 OCR 结果整理为追加到 Prompt 的提示文本，无可用结果时返回空串
+func BuildOcrHint(result *OcrResult) string {
+	if result == nil || (len(result.Dates) == 0 && len(result.Times) == 0) {
+		return ""
+	}
+	dates, times := "无", "无"
+	if len(result.Dates) > 0 {
+		dates = strings.Join(result.Dates, "、")
+	}
+	if len(result.Times) > 0 {
+		times = strings.Join(result.Times, "、")
+	}
+	return fmt.Sprintf(`
+
+## OCR 预识别结果（供参考，读取图片中的数字时请优先核对以下结果）:
+- 识别到的日期: %s
+- 识别到的时间: %s`, dates, times)
+}
@@ -0,0 +1,868 @@
+package client
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PdfPageImage PDF 单页提取出的图片
+type PdfPageImage struct {
+	Page    int    // 页码（从1开始）
+	DataURI string // 图片 data URI（image/jpeg），提取失败时为空
+	Err     error  // 该页提取失败原因
+}
+
+// maxPdfStreamBytes 对象流、调色板等非图片流解压后的大小上限，防止解压炸弹
+const maxPdfStreamBytes = 4 << 20
+
+// errPdfStreamTooLarge 流解压后超过大小上限
+var errPdfStreamTooLarge = errors.New("PDF 流解压后超过大小上限")
+
+// IsPdf 判断字节内容是否为 PDF
+func IsPdf(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF-"))
+}
+
+// IsPdfURL 根据 URL 后缀或 data URI 类型判断是否为 PDF
+func IsPdfURL(url string) bool {
+	if strings.HasPrefix(url, "data:") {
+		return strings.HasPrefix(url, "data:application/pdf")
+	}
+	path := url
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.HasSuffix(strings.ToLower(strings.TrimRight(path, "/")), ".pdf")
+}
+
+// ExtractPdfPageImages 提取 PDF 前 maxPages 页中的图片（每页取面积最大的嵌入图片）
+// 纯 Go 实现，不做矢量/文字渲染；扫描件与医院 App 导出的图片型 PDF 可直接使用
+// maxPixels>0 时跳过超过像素上限的图片，防止解压炸弹
+func ExtractPdfPageImages(data []byte, maxPages int, maxPixels int) ([]PdfPageImage, error) {
+	if !IsPdf(data) {
+		return nil, fmt.Errorf("不是有效的 PDF 文件")
+	}
+	if pdfEncrypt.Match(data) {
+		return nil, fmt.Errorf("不支持加密的 PDF 文件")
+	}
+
+	doc := parsePdfDocument(data)
+	pages := doc.pages()
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("PDF 中未找到页面")
+	}
+	log.Printf("PDF 解析完成 - 对象数: %d, 页数: %d, 最多处理: %d 页", len(doc.offsets), len(pages), maxPages)
+	if maxPages > 0 && len(pages) > maxPages {
+		pages = pages[:maxPages]
+	}
+
+	out := make([]PdfPageImage, 0, len(pages))
+	for i, page := range pages {
+		result := PdfPageImage{Page: i + 1}
+		stream := doc.largestPageImage(page.resources)
+		if stream == nil {
+			result.Err = fmt.Errorf("第 %d 页不含可提取的图片（纯文本 PDF 暂不支持渲染）", i+1)
+			out = append(out, result)
+			continue
+		}
+		jpegBytes, err := doc.imageToJpeg(stream, maxPixels)
+		if err != nil {
+			result.Err = fmt.Errorf("第 %d 页图片解码失败: %w", i+1, err)
+		} else {
+			result.DataURI = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(jpegBytes)
+		}
+		out = append(out, result)
+	}
+	return out, nil
+}
+
+// --- PDF 对象模型 ---
+
+type pdfName string
+
+type pdfRef struct{ num, gen int }
+
+type pdfDict map[string]interface{}
+
+type pdfStream struct {
+	dict pdfDict
+	raw  []byte
+}
+
+type pdfPage struct {
+	resources pdfDict
+}
+
+type pdfDocument struct {
+	data    []byte
+	offsets map[int]int         // 直接对象：对象号 -> 值起始偏移
+	objects map[int]interface{} // 已解析对象缓存（含对象流中的对象）
+}
+
+var (
+	pdfObjHeader  = regexp.MustCompile(`(?:^|[\r\n])\s*(\d+)\s+(\d+)\s+obj\b`)
+	pdfEncrypt    = regexp.MustCompile(`/Encrypt\s*(<<|\d+\s+\d+\s+R)`) // trailer 中的加密字典
+	pdfNameEscape = regexp.MustCompile(`#[0-9A-Fa-f]{2}`)               // 名称中的 #xx 转义
+)
+
+// parsePdfDocument 扫描全部 "n g obj" 定义（后出现者覆盖先出现者，兼容增量更新），并展开对象流
+func parsePdfDocument(data []byte) *pdfDocument {
+	doc := &pdfDocument{data: data, offsets: map[int]int{}, objects: map[int]interface{}{}}
+	for _, m := range pdfObjHeader.FindAllSubmatchIndex(data, -1) {
+		num, err := strconv.Atoi(string(data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		doc.offsets[num] = m[1]
+	}
+
+	for num := range doc.offsets {
+		st, ok := doc.object(num).(*pdfStream)
+		if !ok || st.dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		doc.expandObjectStream(st)
+	}
+	return doc
+}
+
+// object 按对象号取对象（懒解析）
+func (d *pdfDocument) object(num int) interface{} {
+	if obj, ok := d.objects[num]; ok {
+		return obj
+	}
+	offset, ok := d.offsets[num]
+	if !ok {
+		return nil
+	}
+	d.objects[num] = nil // 防止 /Length 等循环引用导致无限递归
+	p := &pdfParser{data: d.data, pos: offset}
+	obj, err := p.parseObject()
+	if err != nil {
+		return nil
+	}
+	if dict, ok := obj.(pdfDict); ok {
+		p.skipSpace()
+		if bytes.HasPrefix(d.data[p.pos:], []byte("stream")) {
+			obj = d.readStream(dict, p.pos+len("stream"))
+		}
+	}
+	d.objects[num] = obj
+	return obj
+}
+
+// readStream 读取 stream 与 endstream 之间的原始数据
+func (d *pdfDocument) readStream(dict pdfDict, pos int) *pdfStream {
+	if pos < len(d.data) && d.data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(d.data) && d.data[pos] == '\n' {
+		pos++
+	}
+	if length, ok := d.resolve(dict["Length"]).(int); ok && length >= 0 && pos+length <= len(d.data) {
+		end := pos + length
+		rest := bytes.TrimLeft(d.data[end:min(end+32, len(d.data))], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return &pdfStream{dict: dict, raw: d.data[pos:end]}
+		}
+	}
+	end := bytes.Index(d.data[pos:], []byte("endstream"))
+	if end < 0 {
+		return &pdfStream{dict: dict, raw: d.data[pos:]}
+	}
+	return &pdfStream{dict: dict, raw: bytes.TrimRight(d.data[pos:pos+end], "\r\n")}
+}
+
+// expandObjectStream 展开对象流（PDF 1.5+）中的压缩对象
+func (d *pdfDocument) expandObjectStream(st *pdfStream) {
+	content, _, err := d.decodeStream(st, maxPdfStreamBytes)
+	if err != nil {
+		return
+	}
+	n, _ := d.resolve(st.dict["N"]).(int)
+	first, _ := d.resolve(st.dict["First"]).(int)
+	if n <= 0 || first <= 0 || first > len(content) {
+		return
+	}
+	header := strings.Fields(string(content[:first]))
+	for i := 0; i+1 < len(header) && i/2 < n; i += 2 {
+		num, err1 := strconv.Atoi(header[i])
+		off, err2 := strconv.Atoi(header[i+1])
+		if err1 != nil || err2 != nil || first+off >= len(content) {
+			continue
+		}
+		if _, exists := d.offsets[num]; exists {
+			continue
+		}
+		if _, exists := d.objects[num]; exists {
+			continue
+		}
+		p := &pdfParser{data: content, pos: first + off}
+		if obj, err := p.parseObject(); err == nil {
+			d.objects[num] = obj
+		}
+	}
+}
+
+// resolve 解引用
+func (d *pdfDocument) resolve(v interface{}) interface{} {
+	for depth := 0; depth < 8; depth++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = d.object(ref.num)
+	}
+	return nil
+}
+
+func (d *pdfDocument) dict(v interface{}) pdfDict {
+	switch t := d.resolve(v).(type) {
+	case pdfDict:
+		return t
+	case *pdfStream:
+		return t.dict
+	}
+	return nil
+}
+
+// pages 按页面树顺序返回页面（Resources 支持从父节点继承）
+func (d *pdfDocument) pages() []pdfPage {
+	var root pdfDict
+	for num := range d.offsets {
+		if dict := d.dict(pdfRef{num: num}); dict != nil && dict["Type"] == pdfName("Catalog") {
+			root = d.dict(dict["Pages"])
+			break
+		}
+	}
+	if root == nil {
+		for num := range d.objects {
+			if dict := d.dict(pdfRef{num: num}); dict != nil && dict["Type"] == pdfName("Catalog") {
+				root = d.dict(dict["Pages"])
+				break
+			}
+		}
+	}
+	if root == nil {
+		return nil
+	}
+
+	var pages []pdfPage
+	var walk func(node pdfDict, inherited pdfDict, depth int)
+	walk = func(node pdfDict, inherited pdfDict, depth int) {
+		if node == nil || depth > 32 {
+			return
+		}
+		resources := inherited
+		if r := d.dict(node["Resources"]); r != nil {
+			resources = r
+		}
+		if node["Type"] == pdfName("Page") {
+			pages = append(pages, pdfPage{resources: resources})
+			return
+		}
+		kids, _ := d.resolve(node["Kids"]).([]interface{})
+		for _, kid := range kids {
+			walk(d.dict(kid), resources, depth+1)
+		}
+	}
+	walk(root, nil, 0)
+	return pages
+}
+
+// largestPageImage 在页面资源（含 Form XObject）中查找面积最大的图片
+func (d *pdfDocument) largestPageImage(resources pdfDict) *pdfStream {
+	var best *pdfStream
+	bestArea := 0
+	var visit func(res pdfDict, depth int)
+	visit = func(res pdfDict, depth int) {
+		if res == nil || depth > 4 {
+			return
+		}
+		for _, v := range d.dict(res["XObject"]) {
+			st, ok := d.resolve(v).(*pdfStream)
+			if !ok {
+				continue
+			}
+			switch st.dict["Subtype"] {
+			case pdfName("Image"):
+				w, _ := d.resolve(st.dict["Width"]).(int)
+				h, _ := d.resolve(st.dict["Height"]).(int)
+				if w*h > bestArea {
+					best, bestArea = st, w*h
+				}
+			case pdfName("Form"):
+				visit(d.dict(st.dict["Resources"]), depth+1)
+			}
+		}
+	}
+	visit(resources, 0)
+	return best
+}
+
+// --- 流解码 ---
+
+// decodeStream 依次执行非图像压缩类过滤器，返回解码后的数据与剩余的图像编码过滤器名（如 DCTDecode）
+// limit 为 FlateDecode 解压后的字节上限，超过时返回错误
+func (d *pdfDocument) decodeStream(st *pdfStream, limit int) ([]byte, string, error) {
+	var filters []interface{}
+	switch f := d.resolve(st.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []interface{}{f}
+	case []interface{}:
+		filters = f
+	}
+	var params []interface{}
+	switch p := d.resolve(st.dict["DecodeParms"]).(type) {
+	case pdfDict:
+		params = []interface{}{p}
+	case []interface{}:
+		params = p
+	}
+
+	data := st.raw
+	for i, f := range filters {
+		name, _ := d.resolve(f).(pdfName)
+		var param pdfDict
+		if i < len(params) {
+			param = d.dict(params[i])
+		}
+		var err error
+		switch name {
+		case "FlateDecode", "Fl":
+			data, err = inflate(data, limit)
+			if err == nil {
+				data, err = d.applyPredictor(data, param)
+			}
+		case "ASCIIHexDecode", "AHx":
+			cleaned := strings.Map(func(r rune) rune {
+				if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+					return r
+				}
+				return -1
+			}, strings.SplitN(string(data), ">", 2)[0])
+			if len(cleaned)%2 == 1 {
+				cleaned += "0"
+			}
+			data, err = hex.DecodeString(cleaned)
+		case "ASCII85Decode", "A85":
+			text := strings.TrimPrefix(strings.TrimSpace(string(data)), "<~")
+			text = strings.SplitN(text, "~>", 2)[0]
+			buf := make([]byte, len(text))
+			var n int
+			n, _, err = ascii85.Decode(buf, []byte(text), true)
+			data = buf[:n]
+		case "DCTDecode", "DCT", "JPXDecode", "JBIG2Decode", "CCITTFaxDecode", "CCF":
+			if i != len(filters)-1 {
+				return nil, "", fmt.Errorf("不支持的过滤器组合: %v", filters)
+			}
+			return data, string(name), nil
+		default:
+			return nil, "", fmt.Errorf("不支持的过滤器: %s", name)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("%s 解码失败: %w", name, err)
+		}
+	}
+	return data, "", nil
+}
+
+// inflate 解压 zlib（部分 PDF 生成器写出的是无 zlib 头的裸 deflate），解压后超过 limit 字节时返回错误
+func inflate(data []byte, limit int) ([]byte, error) {
+	if r, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		out, err := inflateLimited(r, limit)
+		if errors.Is(err, errPdfStreamTooLarge) {
+			return nil, err
+		}
+		if err == nil || len(out) > 0 {
+			return out, nil
+		}
+	}
+	return inflateLimited(flate.NewReader(bytes.NewReader(data)), limit)
+}
+
+// inflateLimited 最多读取 limit 字节，超过时返回 errPdfStreamTooLarge；解压出错时保留已读出的部分
+func inflateLimited(r io.Reader, limit int) ([]byte, error) {
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if len(out) > limit {
+		return nil, fmt.Errorf("%w（%d 字节）", errPdfStreamTooLarge, limit)
+	}
+	return out, err
+}
+
+// applyPredictor 还原 PNG 预测器（Predictor >= 10）
+func (d *pdfDocument) applyPredictor(data []byte, param pdfDict) ([]byte, error) {
+	predictor, _ := d.resolve(param["Predictor"]).(int)
+	if predictor < 10 {
+		return data, nil
+	}
+	colors, columns, bpc := 1, 1, 8
+	if v, ok := d.resolve(param["Colors"]).(int); ok && v > 0 {
+		colors = v
+	}
+	if v, ok := d.resolve(param["Columns"]).(int); ok && v > 0 {
+		columns = v
+	}
+	if v, ok := d.resolve(param["BitsPerComponent"]).(int); ok && v > 0 {
+		bpc = v
+	}
+	bpp := max(1, colors*bpc/8)
+	rowLen := (colors*bpc*columns + 7) / 8
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for pos := 0; pos+rowLen+1 <= len(data); pos += rowLen + 1 {
+		filterType := data[pos]
+		row := append([]byte(nil), data[pos+1:pos+1+rowLen]...)
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = row[i-bpp], prev[i-bpp]
+			}
+			up := prev[i]
+			switch filterType {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// imageToJpeg 将 PDF 图片 XObject 转为 JPEG 字节
+func (d *pdfDocument) imageToJpeg(st *pdfStream, maxPixels int) ([]byte, error) {
+	width, _ := d.resolve(st.dict["Width"]).(int)
+	height, _ := d.resolve(st.dict["Height"]).(int)
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("图片尺寸无效")
+	}
+	if maxPixels > 0 && width*height > maxPixels {
+		return nil, fmt.Errorf("图片尺寸 %dx%d 超过像素上限 %d", width, height, maxPixels)
+	}
+
+	data, encoding, err := d.decodeStream(st, d.imageStreamLimit(st, width, height))
+	if err != nil {
+		return nil, err
+	}
+	switch encoding {
+	case "DCTDecode", "DCT":
+		return data, nil
+	case "":
+	default:
+		return nil, fmt.Errorf("暂不支持 %s 编码的图片", encoding)
+	}
+
+	bpc, _ := d.resolve(st.dict["BitsPerComponent"]).(int)
+	if mask, _ := d.resolve(st.dict["ImageMask"]).(bool); mask {
+		bpc = 1
+	}
+	img, err := d.buildRawImage(st, data, width, height, bpc)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, fmt.Errorf("JPEG 编码失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// imageStreamLimit 图片流解压后的字节上限：像素数据为 宽×高×通道数（按色深换算），
+// 加上 PNG 预测器每行 1 字节的类型标记；以 DCTDecode 等图像编码结尾的流解压出的是编码后的数据，按固定上限
+func (d *pdfDocument) imageStreamLimit(st *pdfStream, width, height int) int {
+	filter := d.resolve(st.dict["Filter"])
+	if filters, ok := filter.([]interface{}); ok && len(filters) > 0 {
+		filter = d.resolve(filters[len(filters)-1])
+	}
+	switch filter {
+	case pdfName("DCTDecode"), pdfName("DCT"), pdfName("JPXDecode"), pdfName("JBIG2Decode"), pdfName("CCITTFaxDecode"), pdfName("CCF"):
+		return maxPdfStreamBytes
+	}
+	// JPEG 编码的尺寸上限，同时避免下方乘法溢出
+	if width > 65535 || height > 65535 {
+		return 0
+	}
+
+	bpc, _ := d.resolve(st.dict["BitsPerComponent"]).(int)
+	if mask, _ := d.resolve(st.dict["ImageMask"]).(bool); mask {
+		bpc = 1
+	}
+	bpc = min(max(bpc, 1), 16)
+	comps := 1
+	switch cs := d.resolve(st.dict["ColorSpace"]).(type) {
+	case pdfName:
+		comps = colorSpaceComponents(cs)
+	case []interface{}:
+		if len(cs) > 0 {
+			switch family, _ := d.resolve(cs[0]).(pdfName); family {
+			case "ICCBased":
+				if len(cs) > 1 {
+					if n, ok := d.resolve(d.dict(cs[1])["N"]).(int); ok && n > 0 {
+						comps = n
+					}
+				}
+			case "Indexed", "I":
+			default:
+				comps = colorSpaceComponents(family)
+			}
+		}
+	}
+	rowLen := (width*comps*bpc + 7) / 8
+	return (rowLen + 1) * height
+}
+
+// buildRawImage 由未压缩像素数据构建图片（支持 Gray/RGB/CMYK/Indexed 8 位与 1 位灰度）
+func (d *pdfDocument) buildRawImage(st *pdfStream, data []byte, width, height, bpc int) (image.Image, error) {
+	comps, palette := 1, []byte(nil)
+	switch cs := d.resolve(st.dict["ColorSpace"]).(type) {
+	case pdfName:
+		comps = colorSpaceComponents(cs)
+	case []interface{}:
+		if len(cs) == 0 {
+			break
+		}
+		family, _ := d.resolve(cs[0]).(pdfName)
+		switch family {
+		case "ICCBased":
+			if len(cs) > 1 {
+				if n, ok := d.resolve(d.dict(cs[1])["N"]).(int); ok {
+					comps = n
+				}
+			}
+		case "Indexed", "I":
+			if len(cs) < 4 {
+				return nil, fmt.Errorf("Indexed 颜色空间参数不足")
+			}
+			base := 3
+			if name, ok := d.resolve(cs[1]).(pdfName); ok {
+				base = colorSpaceComponents(name)
+			}
+			if base != 3 {
+				return nil, fmt.Errorf("暂不支持非 RGB 基色的 Indexed 颜色空间")
+			}
+			switch lookup := d.resolve(cs[3]).(type) {
+			case string:
+				palette = []byte(lookup)
+			case *pdfStream:
+				palette, _, _ = d.decodeStream(lookup, maxPdfStreamBytes)
+			}
+		default:
+			comps = colorSpaceComponents(family)
+		}
+	}
+
+	rect := image.Rect(0, 0, width, height)
+	if bpc == 1 {
+		invert := false
+		if decode, ok := d.resolve(st.dict["Decode"]).([]interface{}); ok && len(decode) == 2 {
+			invert = d.resolve(decode[0]) == 1
+		}
+		rowLen := (width + 7) / 8
+		if len(data) < rowLen*height {
+			return nil, fmt.Errorf("像素数据长度不足")
+		}
+		img := image.NewGray(rect)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				bit := data[y*rowLen+x/8]>>(7-uint(x%8))&1 == 1
+				if bit != invert {
+					img.Pix[y*img.Stride+x] = 255
+				}
+			}
+		}
+		return img, nil
+	}
+	if bpc != 8 {
+		return nil, fmt.Errorf("暂不支持 %d 位色深", bpc)
+	}
+
+	if palette != nil {
+		if len(data) < width*height {
+			return nil, fmt.Errorf("像素数据长度不足")
+		}
+		img := image.NewRGBA(rect)
+		for i := 0; i < width*height; i++ {
+			idx := int(data[i]) * 3
+			if idx+2 < len(palette) {
+				img.Pix[i*4], img.Pix[i*4+1], img.Pix[i*4+2] = palette[idx], palette[idx+1], palette[idx+2]
+			}
+			img.Pix[i*4+3] = 255
+		}
+		return img, nil
+	}
+
+	if len(data) < width*height*comps {
+		return nil, fmt.Errorf("像素数据长度不足")
+	}
+	switch comps {
+	case 1:
+		img := image.NewGray(rect)
+		copy(img.Pix, data[:width*height])
+		return img, nil
+	case 3:
+		img := image.NewRGBA(rect)
+		for i := 0; i < width*height; i++ {
+			img.Pix[i*4], img.Pix[i*4+1], img.Pix[i*4+2], img.Pix[i*4+3] = data[i*3], data[i*3+1], data[i*3+2], 255
+		}
+		return img, nil
+	case 4:
+		img := image.NewRGBA(rect)
+		for i := 0; i < width*height; i++ {
+			r, g, b := color.CMYKToRGB(data[i*4], data[i*4+1], data[i*4+2], data[i*4+3])
+			img.Pix[i*4], img.Pix[i*4+1], img.Pix[i*4+2], img.Pix[i*4+3] = r, g, b, 255
+		}
+		return img, nil
+	}
+	return nil, fmt.Errorf("暂不支持 %d 通道的颜色空间", comps)
+}
+
+func colorSpaceComponents(name pdfName) int {
+	switch name {
+	case "DeviceRGB", "RGB", "CalRGB":
+		return 3
+	case "DeviceCMYK", "CMYK":
+		return 4
+	}
+	return 1
+}
+
+// --- 词法/语法解析 ---
+
+type pdfParser struct {
+	data []byte
+	pos  int
+}
+
+func isPdfSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPdfDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (p *pdfParser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isPdfSpace(c) {
+			p.pos++
+		} else if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+func (p *pdfParser) readToken() string {
+	start := p.pos
+	for p.pos < len(p.data) && !isPdfSpace(p.data[p.pos]) && !isPdfDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+func (p *pdfParser) parseObject() (interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	switch c := p.data[p.pos]; {
+	case c == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		p.pos += 2
+		dict := pdfDict{}
+		for {
+			p.skipSpace()
+			if p.pos+1 >= len(p.data) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			if p.data[p.pos] == '>' && p.data[p.pos+1] == '>' {
+				p.pos += 2
+				return dict, nil
+			}
+			key, err := p.parseObject()
+			if err != nil {
+				return nil, err
+			}
+			name, ok := key.(pdfName)
+			if !ok {
+				return nil, fmt.Errorf("字典键不是名称: %v", key)
+			}
+			value, err := p.parseObject()
+			if err != nil {
+				return nil, err
+			}
+			dict[string(name)] = value
+		}
+	case c == '<':
+		end := bytes.IndexByte(p.data[p.pos:], '>')
+		if end < 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		raw := strings.Join(strings.Fields(string(p.data[p.pos+1:p.pos+end])), "")
+		p.pos += end + 1
+		if len(raw)%2 == 1 {
+			raw += "0"
+		}
+		decoded, err := hex.DecodeString(raw)
+		return string(decoded), err
+	case c == '[':
+		p.pos++
+		var arr []interface{}
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.data) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			if p.data[p.pos] == ']' {
+				p.pos++
+				return arr, nil
+			}
+			v, err := p.parseObject()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+	case c == '(':
+		return p.parseLiteralString()
+	case c == '/':
+		p.pos++
+		name := p.readToken()
+		if strings.Contains(name, "#") {
+			name = pdfNameEscape.ReplaceAllStringFunc(name, func(m string) string {
+				b, _ := hex.DecodeString(m[1:])
+				return string(b)
+			})
+		}
+		return pdfName(name), nil
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		token := p.readToken()
+		if n, err := strconv.Atoi(token); err == nil {
+			// 尝试识别间接引用 "n g R"
+			save := p.pos
+			p.skipSpace()
+			gen := p.readToken()
+			p.skipSpace()
+			if g, err := strconv.Atoi(gen); err == nil && p.pos < len(p.data) && p.data[p.pos] == 'R' &&
+				(p.pos+1 == len(p.data) || isPdfSpace(p.data[p.pos+1]) || isPdfDelimiter(p.data[p.pos+1])) {
+				p.pos++
+				return pdfRef{num: n, gen: g}, nil
+			}
+			p.pos = save
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(token, 64)
+		return f, err
+	default:
+		switch token := p.readToken(); token {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		case "":
+			p.pos++
+			return nil, fmt.Errorf("意外的字符 %q", c)
+		default:
+			return pdfName(token), nil // 关键字（如 obj/endobj）按名称返回，由调用方忽略
+		}
+	}
+}
+
+func (p *pdfParser) parseLiteralString() (interface{}, error) {
+	p.pos++ // 跳过 '('
+	var buf bytes.Buffer
+	depth := 1
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '\\':
+			if p.pos < len(p.data) {
+				next := p.data[p.pos]
+				p.pos++
+				switch next {
+				case 'n':
+					buf.WriteByte('\n')
+				case 'r':
+					buf.WriteByte('\r')
+				case 't':
+					buf.WriteByte('\t')
+				case 'b':
+					buf.WriteByte('\b')
+				case 'f':
+					buf.WriteByte('\f')
+				case '\r', '\n':
+				default:
+					if next >= '0' && next <= '7' {
+						v := int(next - '0')
+						for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+							v = v*8 + int(p.data[p.pos]-'0')
+							p.pos++
+						}
+						buf.WriteByte(byte(v))
+					} else {
+						buf.WriteByte(next)
+					}
+				}
+			}
+		case '(':
+			depth++
+			buf.WriteByte(c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return buf.String(), nil
+			}
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return nil, io.ErrUnexpectedEOF
+}
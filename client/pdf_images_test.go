@@ -0,0 +1,207 @@
+package client
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"strings"
+	"testing"
+)
+
+// buildPdf 按顺序拼接对象（对象号从 1 开始），不写 xref：解析器按 "n g obj" 扫描对象
+func buildPdf(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func imageObject(dict string, data []byte) string {
+	return fmt.Sprintf("<< /Type /XObject /Subtype /Image %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func decodePageImage(t *testing.T, page PdfPageImage) image.Image {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(page.DataURI, "data:image/jpeg;base64,"))
+	if err != nil {
+		t.Fatalf("第 %d 页 data URI 无效: %v", page.Page, err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("第 %d 页不是有效的 JPEG: %v", page.Page, err)
+	}
+	return img
+}
+
+func TestExtractPdfPageImages(t *testing.T) {
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, image.NewGray(image.Rect(0, 0, 8, 6)), nil); err != nil {
+		t.Fatal(err)
+	}
+	rgb := bytes.Repeat([]byte{255, 0, 0}, 4*3)
+	pdf := buildPdf(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		// 第 1、2 页继承页面树上的资源，第 3 页自带资源，第 4 页为纯文本
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R 6 0 R] /Count 4 /Resources << /XObject << /Im1 7 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Small 8 0 R /Im1 7 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Fm 9 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << >> >>",
+		imageObject("/Width 4 /Height 3 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode", deflate(rgb)),
+		imageObject("/Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8", []byte{0}),
+		"<< /Type /XObject /Subtype /Form /Resources << /XObject << /Photo 10 0 R >> >> /Length 0 >>\nstream\n\nendstream",
+		imageObject("/Width 8 /Height 6 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode", jpg.Bytes()),
+	)
+
+	pages, err := ExtractPdfPageImages(pdf, 0, 0)
+	if err != nil {
+		t.Fatalf("ExtractPdfPageImages: %v", err)
+	}
+	if len(pages) != 4 {
+		t.Fatalf("页数 = %d, want 4", len(pages))
+	}
+	sizes := []image.Point{{4, 3}, {4, 3}, {8, 6}}
+	for i, want := range sizes {
+		if pages[i].Page != i+1 || pages[i].Err != nil {
+			t.Fatalf("第 %d 页: page=%d err=%v", i+1, pages[i].Page, pages[i].Err)
+		}
+		if got := decodePageImage(t, pages[i]).Bounds().Size(); got != want {
+			t.Errorf("第 %d 页图片尺寸 = %v, want %v", i+1, got, want)
+		}
+	}
+	if pages[3].Err == nil || pages[3].DataURI != "" {
+		t.Errorf("纯文本页应返回错误，got %+v", pages[3])
+	}
+
+	if pages, err := ExtractPdfPageImages(pdf, 2, 0); err != nil || len(pages) != 2 {
+		t.Errorf("maxPages=2: 页数 %d, err %v", len(pages), err)
+	}
+	pages, err = ExtractPdfPageImages(pdf, 0, 20)
+	if err != nil {
+		t.Fatalf("ExtractPdfPageImages: %v", err)
+	}
+	if pages[0].Err != nil || pages[2].Err == nil || !strings.Contains(pages[2].Err.Error(), "像素上限") {
+		t.Errorf("maxPixels=20: 第 1 页 err=%v，第 3 页 err=%v", pages[0].Err, pages[2].Err)
+	}
+}
+
+func TestExtractPdfPageImagesInflateLimit(t *testing.T) {
+	// 带 PNG 预测器的 4x3 RGB 图片：每行 1 字节预测类型 + 12 字节像素，恰好等于上限
+	var predicted []byte
+	for y := 0; y < 3; y++ {
+		predicted = append(predicted, 0)
+		predicted = append(predicted, bytes.Repeat([]byte{0, 128, 255}, 4)...)
+	}
+	// 解压炸弹：10x10 灰度图的 Flate 流解压后为 1 MB
+	bomb := deflate(make([]byte, 1<<20))
+	pdf := buildPdf(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Im1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Im1 6 0 R >> >> >>",
+		imageObject("/Width 4 /Height 3 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /DecodeParms << /Predictor 15 /Colors 3 /Columns 4 >>", deflate(predicted)),
+		imageObject("/Width 10 /Height 10 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode", bomb),
+	)
+	if len(pdf) > 4<<10 {
+		t.Fatalf("炸弹样本应很小，实际 %d 字节", len(pdf))
+	}
+
+	pages, err := ExtractPdfPageImages(pdf, 0, 0)
+	if err != nil {
+		t.Fatalf("ExtractPdfPageImages: %v", err)
+	}
+	if pages[0].Err != nil {
+		t.Errorf("预测器图片不应超限: %v", pages[0].Err)
+	}
+	if pages[1].Err == nil || !errors.Is(pages[1].Err, errPdfStreamTooLarge) {
+		t.Errorf("解压炸弹应返回 errPdfStreamTooLarge, got %v", pages[1].Err)
+	}
+}
+
+func TestInflateLimit(t *testing.T) {
+	data := deflate(make([]byte, maxPdfStreamBytes+1))
+	if _, err := inflate(data, maxPdfStreamBytes); !errors.Is(err, errPdfStreamTooLarge) {
+		t.Errorf("超过上限应返回 errPdfStreamTooLarge, got %v", err)
+	}
+	if out, err := inflate(data, maxPdfStreamBytes+1); err != nil || len(out) != maxPdfStreamBytes+1 {
+		t.Errorf("未超过上限: %d 字节, err %v", len(out), err)
+	}
+	// 无 zlib 头的裸 deflate 同样受限
+	var raw bytes.Buffer
+	w, _ := flate.NewWriter(&raw, flate.BestCompression)
+	w.Write(make([]byte, 1024))
+	w.Close()
+	if _, err := inflate(raw.Bytes(), 100); !errors.Is(err, errPdfStreamTooLarge) {
+		t.Errorf("裸 deflate 超过上限应返回 errPdfStreamTooLarge, got %v", err)
+	}
+}
+
+func TestExtractPdfPageImagesRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"非 PDF", []byte("\x89PNG\r\n"), "不是有效的 PDF"},
+		{"加密", buildPdf("<< /Type /Catalog /Pages 2 0 R >>", "<< /Filter /Standard >>", "<< /Encrypt 2 0 R >>"), "加密"},
+		{"无页面", buildPdf("<< /Type /Catalog /Pages 2 0 R >>", "<< /Type /Pages /Kids [] /Count 0 >>"), "未找到页面"},
+	}
+	for _, tt := range tests {
+		if _, err := ExtractPdfPageImages(tt.data, 0, 0); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want 包含 %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestIsPdf(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"%PDF-1.7\n", true},
+		{"\r\n %PDF-1.4", true},
+		{"\xff\xd8\xff", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsPdf([]byte(tt.in)); got != tt.want {
+			t.Errorf("IsPdf(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsPdfURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"https://oa.example.com/files/诊断证明.pdf", true},
+		{"https://oa.example.com/files/a.PDF?token=1#page=2", true},
+		{"https://oa.example.com/files/a.pdf/", true},
+		{"data:application/pdf;base64,JVBERi0=", true},
+		{"data:image/png;base64,iVBORw0=", false},
+		{"https://oa.example.com/download?file=a.pdf", false},
+		{"https://oa.example.com/a.jpg", false},
+	}
+	for _, tt := range tests {
+		if got := IsPdfURL(tt.in); got != tt.want {
+			t.Errorf("IsPdfURL(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
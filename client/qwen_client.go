@@ -39,7 +39,7 @@ func NewQwenClient(url string, apiKey string) *QwenClient {
 // ExtractDataFromImage 调用 Qwen API 提取图片数据
 // 支持 fileHeader（直接上传）或 imageURL（URL直传）
 // 返回：提取的数据、请求ID、Token使用情况、错误
-func (c *QwenClient) ExtractDataFromImage(fileHeader *multipart.FileHeader, imageURL string, officialName string, appType string, applicationDate string, appStart string, appEnd string) (*model.ExtractedData, string, *model.TokenUsage, error) {
+func (c *QwenClient) ExtractDataFromImage(fileHeader *multipart.FileHeader, imageURL string, officialName string, appType string, applicationDate string, appStart string, appEnd string, ocrHint string) (*model.ExtractedData, string, *model.TokenUsage, error) {
 	startTime := time.Now()
 
 	// 记录输入来源
@@ -62,11 +62,11 @@ func (c *QwenClient) ExtractDataFromImage(fileHeader *multipart.FileHeader, imag
 	log.Printf("图片内容构建完成 (耗时: %v)", imageDuration)
 
 	// 2. 构建prompt
-	promptText := buildExtractorPrompt(officialName, appType, applicationDate, appStart, appEnd)
+	promptText := buildExtractorPrompt(officialName, appType, applicationDate, appStart, appEnd) + ocrHint
 
 	// 3. 构建请求体 (!! 使用 Qwen 特有结构 !!)
 	reqBody := QwenVisionRequest{
-		Model: "qwen3-vl-plus", // <-- 使用 Qwen 模型 ID
+		Model: QwenVisionModel, // <-- 使用 Qwen 模型 ID
 		Messages: []VisionMessage{
 			{
 				Role: "user",
@@ -166,6 +166,8 @@ func (c *QwenClient) ExtractDataFromImage(fileHeader *multipart.FileHeader, imag
 		return nil, requestId, tokenUsage, fmt.Errorf("解析 AI 返回的 JSON 内容失败: %w, AI内容: %s", err, aiContent)
 	}
 	parseDuration := time.Since(parseStartTime)
+	// 日期与时间规整为规则引擎使用的写法（相对日期按申请日期换算）
+	normalizeExtractedData(&extractedData, applicationDate)
 
 	totalDuration := time.Since(startTime)
 	log.Printf("Qwen处理完成 - RequestId: %s, 总耗时: %v (图片处理: %v, HTTP: %v, 读取: %v, 解析: %v)",
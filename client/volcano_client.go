@@ -92,7 +92,7 @@ func NewVolcanoClient(url string, apiKey string) *VolcanoClient {
 // ExtractDataFromImage 调用火山 API 提取图片数据
 // 支持 fileHeader（直接上传）或 imageURL（URL直传）
 // 返回：提取的数据、请求ID、Token使用情况、错误
-func (c *VolcanoClient) ExtractDataFromImage(fileHeader *multipart.FileHeader, imageURL string, officialName string, appType string, applicationDate string, appStart string, appEnd string, needImageValidation bool, attendanceText string) (*model.ExtractedData, string, *model.TokenUsage, error) {
+func (c *VolcanoClient) ExtractDataFromImage(fileHeader *multipart.FileHeader, imageURL string, officialName string, appType string, applicationDate string, appStart string, appEnd string, needImageValidation bool, attendanceText string, ocrHint string) (*model.ExtractedData, string, *model.TokenUsage, error) {
 	startTime := time.Now()
 
 	// 记录输入来源
@@ -134,6 +134,7 @@ func (c *VolcanoClient) ExtractDataFromImage(fileHeader *multipart.FileHeader, i
 		promptText = strings.ReplaceAll(promptText, "{{APPLICATION_TIME}}", displayAppTime(appStart, appEnd))
 		promptText = strings.ReplaceAll(promptText, "{{APPLICATION_TYPE}}", appType)
 		promptText = strings.ReplaceAll(promptText, "{{EMPLOYEE_NAME}}", officialName)
+		promptText += ocrHint
 	} else {
 		promptText = buildNoImagePrompt(officialName, appType, applicationDate, displayAppTime(appStart, appEnd), attendanceText)
 	}
@@ -150,7 +151,7 @@ func (c *VolcanoClient) ExtractDataFromImage(fileHeader *multipart.FileHeader, i
 		}
 	}
 	reqBody := VolcanoVisionRequest{
-		Model:       "doubao-seed-1-6-lite-251015",
+		Model:       VolcanoVisionModel,
 		Messages:    messages,
 		Stream:      false,
 		Temperature: 0.1,
@@ -252,12 +253,7 @@ func (c *VolcanoClient) ExtractDataFromImage(fileHeader *multipart.FileHeader, i
 	if extractedData.RequestType == "" {
 		extractedData.RequestType = appType
 	}
-	if extractedData.RequestDate == "" {
-		extractedData.RequestDate = applicationDate
-	}
-	if extractedData.RequestTime == "" {
-		extractedData.RequestTime = displayAppTime(appStart, appEnd)
-	}
+	// 图片日期/时间不再用申请值兜底，留空表示图片未体现，由规则引擎跳过核对
 	if extractedData.ExtractedName == "" {
 		extractedData.ExtractedName = officialName
 	}
@@ -266,6 +262,8 @@ func (c *VolcanoClient) ExtractDataFromImage(fileHeader *multipart.FileHeader, i
 		extractedData.IsValid = true
 		extractedData.IsProofTypeValid = true
 	}
+	// 日期与时间规整为规则引擎使用的写法（相对日期按申请日期换算）
+	normalizeExtractedData(&extractedData, applicationDate)
 
 	totalDuration := time.Since(startTime)
 	log.Printf("Volcano处理完成 - RequestId: %s, 总耗时: %v (图片处理: %v, HTTP: %v, 读取: %v, 解析: %v)",
@@ -286,7 +284,7 @@ func (c *VolcanoClient) CheckByNoImage(appType string, appName string, appDate s
 
 	// 2. 构建请求体（纯文本，无图片）
 	reqBody := VolcanoVisionRequest{
-		Model: "doubao-seed-1-6-vision-250815", // 使用相同的模型
+		Model: VolcanoTextModel, // 使用相同的模型
 		Messages: []VisionMessage{
 			{
 				Role: "user",
@@ -401,7 +399,7 @@ func (c *VolcanoClient) CheckByWithImageAuth(needImageAuth bool, fileHeader *mul
     if needImageAuth {
         // 需要图片校验，调用带有核验开关与考勤文本的图片分析方法
         attendanceText := strings.Join(attendanceInfo, ", ")
-        return c.ExtractDataFromImage(fileHeader, imageURL, appName, appType, appDate, appStart, appEnd, true, attendanceText)
+        return c.ExtractDataFromImage(fileHeader, imageURL, appName, appType, appDate, appStart, appEnd, true, attendanceText, "")
     } else {
         // 不需要图片校验，调用文本分析方法
         return c.CheckByNoImage(appType, appName, appDate, appStart, appEnd, attendanceInfo)
@@ -3,27 +3,101 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config 结构体存储所有配置
 type Config struct {
 	ServerPort    string // HTTP 服务端口，如 "3000" 或 ":3000"
 	OcrServiceURL string // PaddleOCR 服务的地址
+	OcrEngine     string // OCR 实现: http / stub / none，为空时配置了 OcrServiceURL 即使用 http
+	OcrTimeout    int    // OCR 请求超时（秒）
 	VolcanoApiKey string // 火山 API Key
 	VolcanoApiURL string // 火山 API Endpoint
 	QwenApiKey    string // 通义千问 API Key
 	QwenApiURL    string // 通义千问 API Endpoint
+
+	TamperCheckEnabled bool // 是否对图片进行篡改/编辑痕迹检测
+
+	MaxUploadFiles     int   // 单次请求允许的最大图片数（文件 + URL）
+	MaxUploadFileBytes int64 // 单张图片允许的最大字节数
+	MaxImagePixels     int   // 单张图片允许的最大像素数（宽×高），防止解压炸弹
+	MaxMultipartMemory int64 // multipart 表单解析时驻留内存的上限，超出部分写入临时文件
+	PdfMaxPages        int   // PDF 证据最多处理的页数
+
+	ImageEnhanceProfiles string // 按申请类型配置的图片增强方案，如 "补打卡:crop,contrast,sharpen;病假:crop,grayscale,contrast"
+
+	VerdictCacheEnabled bool          // 是否启用 LLM 判定缓存
+	VerdictCacheSize    int           // 缓存最大条目数（LRU 淘汰）
+	VerdictCacheTTL     time.Duration // 缓存有效期
+	VerdictCacheFile    string        // 缓存落盘文件，为空表示仅内存
+
+	RulesConfigPath string // 规则配置文件（YAML/JSON），为空时使用内置规则
+	VerdictPolicy   string // LLM 与规则的裁决策略，非空时覆盖规则配置中的 verdict_policy
+
+	HolidayDataDir  string // 节假日数据目录（<year>.yaml/json），追加或覆盖内置的节假日与调休数据
+	ShiftConfigPath string // 排班配置文件（班次定义与员工/部门排班），为空时所有人按标准班 09:00~18:00
+
+	CompanyTimezone    string // 公司时区（IANA 时区名或 UTC+8 等固定偏移），未匹配办公地的员工按此时区
+	TimezoneConfigPath string // 办公地时区配置文件（按员工/部门指定时区），为空时所有人按公司时区
+
+	OaBackend    string // OA 实现: rest / mock / none，为空时配置了 OaBaseURL 即使用 rest
+	OaBaseURL    string // OA REST 接口地址
+	OaApiToken   string // OA 接口访问令牌（Bearer）
+	OaTimeout    int    // OA 请求超时（秒）
+	OaMaxRetries int    // OA 请求失败（网络错误、429、5xx）的最大重试次数
+
+	HistoryEnabled       bool   // 是否记录员工历史申请（申请频次核对与近期记录摘要）
+	HistoryFile          string // 申请记录落盘文件，为空表示仅内存
+	HistoryRetentionDays int    // 申请记录保留天数
 }
 
 // LoadConfig 从环境变量加载配置
 func LoadConfig() *Config {
 	cfg := &Config{
-		ServerPort: getEnv("PORT", "3000"),
-		// OcrServiceURL: getEnv("OCR_SERVICE_URL", "http://paddleocr-service:8888/ocr"),
+		ServerPort:    getEnv("PORT", "3000"),
+		OcrServiceURL: getEnv("OCR_SERVICE_URL", ""),
+		OcrEngine:     getEnv("OCR_ENGINE", ""),
+		OcrTimeout:    getEnvInt("OCR_TIMEOUT", 30),
 		VolcanoApiKey: getEnv("VOLCANO_API_KEY", ""),
 		VolcanoApiURL: getEnv("VOLCANO_API_URL", ""),
 		QwenApiKey:    getEnv("QWEN_API_KEY", "sk-fc76b62ec90646d3ae38d02bfb1c3294"),
 		QwenApiURL:    getEnv("QWEN_API_URL", "https://dashscope.aliyuncs.com/api/v1/services/aigc/text-generation/generation"),
+
+		TamperCheckEnabled: getEnvBool("TAMPER_CHECK_ENABLED", true),
+
+		MaxUploadFiles:     getEnvInt("MAX_UPLOAD_FILES", 9),
+		MaxUploadFileBytes: int64(getEnvInt("MAX_UPLOAD_FILE_BYTES", 10<<20)),
+		MaxImagePixels:     getEnvInt("MAX_IMAGE_PIXELS", 40_000_000),
+		MaxMultipartMemory: int64(getEnvInt("MAX_MULTIPART_MEMORY", 8<<20)),
+		PdfMaxPages:        getEnvInt("PDF_MAX_PAGES", 3),
+
+		ImageEnhanceProfiles: getEnv("IMAGE_ENHANCE_PROFILES", ""),
+
+		VerdictCacheEnabled: getEnvBool("VERDICT_CACHE_ENABLED", true),
+		VerdictCacheSize:    getEnvInt("VERDICT_CACHE_SIZE", 1000),
+		VerdictCacheTTL:     getEnvDuration("VERDICT_CACHE_TTL", 24*time.Hour),
+		VerdictCacheFile:    getEnv("VERDICT_CACHE_FILE", ""),
+
+		RulesConfigPath: getEnv("RULES_CONFIG_PATH", ""),
+		VerdictPolicy:   getEnv("VERDICT_POLICY", ""),
+
+		HolidayDataDir:  getEnv("HOLIDAY_DATA_DIR", ""),
+		ShiftConfigPath: getEnv("SHIFT_CONFIG_PATH", ""),
+
+		CompanyTimezone:    getEnv("COMPANY_TIMEZONE", "Asia/Shanghai"),
+		TimezoneConfigPath: getEnv("TIMEZONE_CONFIG_PATH", ""),
+
+		OaBackend:    getEnv("OA_BACKEND", ""),
+		OaBaseURL:    getEnv("OA_BASE_URL", ""),
+		OaApiToken:   getEnv("OA_API_TOKEN", ""),
+		OaTimeout:    getEnvInt("OA_TIMEOUT", 5),
+		OaMaxRetries: getEnvInt("OA_MAX_RETRIES", 2),
+
+		HistoryEnabled:       getEnvBool("HISTORY_ENABLED", true),
+		HistoryFile:          getEnv("HISTORY_FILE", ""),
+		HistoryRetentionDays: getEnvInt("HISTORY_RETENTION_DAYS", 400),
 	}
 
 	// 本地调试时，如果 docker-compose 不在运行，可以回退到 localhost
@@ -44,3 +118,45 @@ func getEnv(key string, fallback string) string {
 	log.Printf("环境变量 %s 未设置, 将使用默认值: %s", key, fallback)
 	return fallback
 }
+
+// 辅助函数：读取布尔型环境变量，解析失败时使用默认值
+func getEnvBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("环境变量 %s 的值 %q 无效, 将使用默认值: %v", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// 辅助函数：读取整型环境变量，解析失败时使用默认值
+func getEnvInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("环境变量 %s 的值 %q 无效, 将使用默认值: %d", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// 辅助函数：读取时长型环境变量（如 "30m"、"24h"），解析失败时使用默认值
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("环境变量 %s 的值 %q 无效, 将使用默认值: %v", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
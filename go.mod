@@ -8,7 +8,7 @@ require (
 	github.com/gin-gonic/gin v1.9.1
 	github.com/joho/godotenv v1.5.1
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
-	golang.org/x/image v0.32.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require github.com/chenzhuoyu/iasm v0.9.0 // indirect
@@ -37,5 +37,4 @@ require (
 	golang.org/x/sys v0.13.0 // indirect; gin 依赖
 	golang.org/x/text v0.30.0 // indirect; gin 依赖
 	google.golang.org/protobuf v1.31.0 // indirect; gin 依赖
-	gopkg.in/yaml.v3 v3.0.1 // indirect; gin 依赖
 )
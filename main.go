@@ -1,9 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"my-ai-app/api"
+	"my-ai-app/calendar"
 	"my-ai-app/config"
+	"my-ai-app/schedule"
+	"my-ai-app/timezone"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -19,7 +29,38 @@ func main() {
 		log.Println("警告: Qwen 或 Volcano 的 API Key 未配置，相关接口可能无法工作")
 	}
 
+	cal, err := calendar.Load(cfg.HolidayDataDir)
+	if err != nil {
+		log.Printf("警告: %v", err)
+	}
+	calendar.SetDefault(cal)
+	log.Printf("节假日数据已加载: %v", cal.Years())
+
+	if cfg.ShiftConfigPath != "" {
+		sched, err := schedule.LoadFile(cfg.ShiftConfigPath)
+		if err != nil {
+			log.Printf("警告: 排班配置加载失败，所有人按标准班处理: %v", err)
+		} else {
+			schedule.SetDefault(sched)
+		}
+	}
+
+	if zones, err := timezone.New(cfg.CompanyTimezone, nil); err != nil {
+		log.Printf("警告: %v，使用默认公司时区 %s", err, timezone.DefaultCompanyTimezone)
+	} else {
+		if cfg.TimezoneConfigPath != "" {
+			if z, err := timezone.LoadFile(cfg.TimezoneConfigPath, cfg.CompanyTimezone); err != nil {
+				log.Printf("警告: 办公地时区配置加载失败，所有人按公司时区处理: %v", err)
+			} else {
+				zones = z
+			}
+		}
+		timezone.SetDefault(zones)
+		log.Printf("公司时区: %s", zones.Company())
+	}
+
 	router := gin.Default()
+	router.MaxMultipartMemory = cfg.MaxMultipartMemory
 	uploadHandler := api.NewUploadHandler(cfg)
 
 	v1 := router.Group("/api/v1")
@@ -36,8 +77,23 @@ func main() {
 	if port[0] != ':' {
 		port = ":" + port
 	}
-	log.Println("服务启动于", port)
-	if err := router.Run(port); err != nil {
-		log.Fatalf("启动服务失败: %v", err)
+	// 收到退出信号后停止接收请求，等待处理中的请求完成，再写入尚未落盘的缓存与记录
+	srv := &http.Server{Addr: port, Handler: router}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		log.Println("服务启动于", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("启动服务失败: %v", err)
+		}
+	}()
+	<-ctx.Done()
+
+	log.Println("正在停止服务...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("停止服务超时: %v", err)
 	}
+	uploadHandler.Close()
 }
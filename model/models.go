@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // ApplicationData OA 系统提交的表单数据
 type ApplicationData struct {
 	UserId              string   `form:"user_id"`                                            // 员工 ID
@@ -9,6 +11,9 @@ type ApplicationData struct {
 	StartTime           string   `form:"start_time"`                                         // 上班时间 (e.g., "09:00")
 	EndTime             string   `form:"end_time"`                                           // 下班时间 (e.g., "18:00")
 	ApplicationDate     string   `form:"application_date"`                                   // 申请的日期 (e.g., "2025-10-21")
+	StartDate           string   `form:"start_date" json:"start_date"`                       // 多日申请的开始日期 (e.g., "2025-10-21")
+	EndDate             string   `form:"end_date" json:"end_date"`                           // 多日申请的结束日期（含当天）
+	Department          string   `form:"department" json:"department"`                       // 员工部门（用于匹配按部门配置的规则参数）
 	Reason              string   `form:"reason"`                                             // 申请理由 (文字)
 	ImageUrl            string   `form:"image_url"`                                          // 图片 URL（单个，向后兼容）
 	ImageUrls           []string `form:"image_urls[]"`                                       // 图片 URLs（多个）
@@ -22,6 +27,7 @@ type ExtractedData struct {
 	RequestDate      string `json:"request_date"`
 	RequestTime      string `json:"request_time"`
 	RequestType      string `json:"request_type"`
+	ProofType        string `json:"proof_type"` // 证明材料类型（如 诊断证明、结婚证、机票）
 	IsProofTypeValid bool   `json:"is_proof_type_valid"`
 	Content          string `json:"content"`
 	// 新增字段用于补打卡特殊判断
@@ -29,6 +35,9 @@ type ExtractedData struct {
 	IsChatRecord      bool     `json:"is_chat_record"`      // 是否为聊天记录
 	TimeFromContent   string   `json:"time_from_content"`   // 从内容中提取的时间
 	CandidateTimes    []string `json:"candidate_times"`     // 候选时间列表（HH:mm，多条聊天记录）
+	// 证明材料覆盖的日期范围（如病假条建议休息的起止日期、行程单往返日期）
+	EvidenceStartDate string `json:"evidence_start_date"`
+	EvidenceEndDate   string `json:"evidence_end_date"`
 	// 新增：直接接收LLM判定结果
 	Approve   bool   `json:"approve"`
 	IsValid   bool   `json:"is_valid"`
@@ -37,26 +46,70 @@ type ExtractedData struct {
 	// 新增：用于测试校验的匹配标记
 	DateMatch bool `json:"date_match"`
 	TimeMatch bool `json:"time_match"`
+	// OCR 预识别的日期（yyyy-MM-dd 或 MM-dd）与时间（HH:mm），由服务层回填，供规则引擎直接核对
+	OcrDates []string `json:"ocr_dates,omitempty"`
+	OcrTimes []string `json:"ocr_times,omitempty"`
+	// 非 LLM 取证得到的篡改风险分（由服务层回填，供规则引擎权衡）
+	TamperRiskScore float64 `json:"-"`
+	// 篡改检测是否命中元数据/编辑软件痕迹；仅有压缩痕迹（转发重压缩、裁剪）时风险分再高也只转人工复核
+	TamperEditMarker bool `json:"-"`
+	// 图片在请求中的序号（由服务层回填，用于规则追踪）
+	ImageIndex int `json:"-"`
 }
 
-// AttendanceData OA系统返回的考勤数据
+// AttendanceData 员工当天的考勤数据（由 OA 提供，供规则引擎与时间校验核对）
 type AttendanceData struct {
-	UserId         string `json:"user_id"`         // 员工ID
-	WorkDate       string `json:"work_date"`       // 工作日期 YYYY-MM-DD
-	WorkStartTime  string `json:"work_start_time"` // 上班时间 HH:mm
-	WorkEndTime    string `json:"work_end_time"`   // 下班时间 HH:mm
-	IsWorkDay      bool   `json:"is_work_day"`     // 是否工作日
-	AttendanceType string `json:"attendance_type"` // 考勤类型: normal, late, absent
+	UserId          string   `json:"user_id"`           // 员工ID
+	WorkDate        string   `json:"work_date"`         // 工作日期 YYYY-MM-DD
+	IsWorkDay       bool     `json:"is_work_day"`       // 是否工作日
+	Status          string   `json:"status"`            // 考勤状态，例如: "正常", "迟到", "早退", "缺卡", "请假中"
+	StandardInTime  string   `json:"standard_in_time"`  // 当天标准上班时间 (HH:mm), e.g., "09:00"；与下班时间均提供时覆盖排班配置的班次时间
+	StandardOutTime string   `json:"standard_out_time"` // 当天标准下班时间 (HH:mm), e.g., "18:00"
+	ClockInTime     string   `json:"clock_in_time"`     // 上班打卡时间 (HH:mm), "" 表示未打卡
+	ClockOutTime    string   `json:"clock_out_time"`    // 下班打卡时间 (HH:mm), "" 表示未打卡
+	Punches         []string `json:"punches,omitempty"` // 当天全部打卡时间 (HH:mm)
+}
+
+// AllPunches 当天全部打卡时间：Punches 与上下班打卡时间（去重）
+func (a *AttendanceData) AllPunches() []string {
+	if a == nil {
+		return nil
+	}
+	var punches []string
+	seen := make(map[string]bool)
+	for _, t := range append(append([]string(nil), a.Punches...), a.ClockInTime, a.ClockOutTime) {
+		if t != "" && !seen[t] {
+			seen[t] = true
+			punches = append(punches, t)
+		}
+	}
+	return punches
 }
 
 // TimeValidationResult 时间验证结果
 type TimeValidationResult struct {
-	IsValid    bool   `json:"is_valid"`    // 是否有效
-	IsWorkDay  bool   `json:"is_work_day"` // 是否工作日
-	IsLate     bool   `json:"is_late"`     // 是否迟到
-	RiskLevel  string `json:"risk_level"`  // 风险级别: low, medium, high
-	Suggestion string `json:"suggestion"`  // 建议
-	Details    string `json:"details"`     // 详细信息
+	IsValid      bool   `json:"is_valid"`           // 是否有效
+	IsWorkDay    bool   `json:"is_work_day"`        // 是否工作日
+	IsLate       bool   `json:"is_late"`            // 是否迟到
+	IsEarlyLeave bool   `json:"is_early_leave"`     // 是否早退
+	Shift        string `json:"shift,omitempty"`    // 当天班次，如 "night 22:00~次日06:00"
+	Timezone     string `json:"timezone,omitempty"` // 员工所在时区，如 "Asia/Singapore"
+	RiskLevel    string `json:"risk_level"`         // 风险级别: low, medium, high
+	Suggestion   string `json:"suggestion"`         // 建议
+	Details      string `json:"details"`            // 详细信息
+}
+
+// TamperCheckResult 图片篡改/编辑痕迹检测结果（非 LLM 取证）
+type TamperCheckResult struct {
+	Checked          bool     `json:"checked"`                     // 是否完成检测
+	RiskScore        float64  `json:"risk_score"`                  // 篡改风险分 0~1
+	RiskLevel        string   `json:"risk_level"`                  // 风险级别: low, medium, high
+	Format           string   `json:"format,omitempty"`            // 图片格式
+	EstimatedQuality int      `json:"estimated_quality,omitempty"` // JPEG 估算质量
+	Software         string   `json:"software,omitempty"`          // 元数据中的软件标识
+	Signals          []string `json:"signals,omitempty"`           // 命中的可疑信号
+	EditMarker       bool     `json:"edit_marker"`                 // 是否命中元数据/编辑软件痕迹（仅有压缩痕迹时为 false）
+	ErrorMessage     string   `json:"error_message,omitempty"`     // 检测失败原因
 }
 
 // TokenUsage Token使用情况
@@ -68,35 +121,86 @@ type TokenUsage struct {
 
 // ImageAnalysisDetail 单张图片的分析详情
 type ImageAnalysisDetail struct {
-	Index            int            `json:"index"`                       // 图片索引（从1开始）
-	Source           string         `json:"source"`                      // 来源：file_upload 或 url_download
-	FileName         string         `json:"file_name,omitempty"`         // 文件名（文件上传时）
-	ImageURL         string         `json:"image_url,omitempty"`         // 图片URL（URL下载时）
-	RequestId        string         `json:"request_id,omitempty"`        // LLM请求ID（用于追踪）
-	TokenUsage       *TokenUsage    `json:"token_usage,omitempty"`       // Token使用情况
-	TotalDurationMs  int64          `json:"total_duration_ms,omitempty"` // 总耗时（毫秒，流式输出时使用）
-	Success          bool           `json:"success"`                     // 是否分析成功
-	ErrorMessage     string         `json:"error_message,omitempty"`     // 错误信息
-	ExtractedData    *ExtractedData `json:"extracted_data,omitempty"`    // 提取的数据
-	ProcessingTimeMs int64          `json:"processing_time_ms"`          // 处理时间（毫秒）
-	IsValid          bool           `json:"is_valid"`                    // 是否为有效证明材料
+	Index            int                `json:"index"`                       // 图片索引（从1开始）
+	Source           string             `json:"source"`                      // 来源：file_upload 或 url_download
+	FileName         string             `json:"file_name,omitempty"`         // 文件名（文件上传时）
+	ImageURL         string             `json:"image_url,omitempty"`         // 图片URL（URL下载时）
+	Page             int                `json:"page,omitempty"`              // PDF 页码（PDF 证据按页分析时）
+	RequestId        string             `json:"request_id,omitempty"`        // LLM请求ID（用于追踪）
+	TokenUsage       *TokenUsage        `json:"token_usage,omitempty"`       // Token使用情况
+	TotalDurationMs  int64              `json:"total_duration_ms,omitempty"` // 总耗时（毫秒，流式输出时使用）
+	Success          bool               `json:"success"`                     // 是否分析成功
+	ErrorMessage     string             `json:"error_message,omitempty"`     // 错误信息
+	ExtractedData    *ExtractedData     `json:"extracted_data,omitempty"`    // 提取的数据
+	ProcessingTimeMs int64              `json:"processing_time_ms"`          // 处理时间（毫秒）
+	IsValid          bool               `json:"is_valid"`                    // 是否为有效证明材料
+	TamperCheck      *TamperCheckResult `json:"tamper_check,omitempty"`      // 篡改检测结果
+	CacheHit         bool               `json:"cache_hit"`                   // 是否命中判定缓存（未调用 LLM）
+	Enhancements     []string           `json:"enhancements,omitempty"`      // 送入 LLM 前实际应用的图片增强变换
+	OcrLines         []string           `json:"ocr_lines,omitempty"`         // OCR 识别出的文本行
 }
 
 // AnalysisResult 是我们 API 统一的返回结构
 type AnalysisResult struct {
-	IsAbnormal      bool                  `json:"is_abnormal"`
-	Reason          string                `json:"reason"`
-	ValidImageIndex int                   `json:"valid_image_index,omitempty"` // 有效图片的索引（从1开始，0表示无）
-	ImagesAnalysis  []ImageAnalysisDetail `json:"images_analysis,omitempty"`   // 所有图片的分析详情
-	TimeValidation  *TimeValidationResult `json:"time_validation,omitempty"`   // 时间验证结果
-	RawText         string                `json:"raw_text,omitempty"`          // 调试文本
+	IsAbnormal       bool                  `json:"is_abnormal"`
+	Reason           string                `json:"reason"`
+	ValidImageIndex  int                   `json:"valid_image_index,omitempty"` // 有效图片的索引（从1开始，0表示无）
+	ImagesAnalysis   []ImageAnalysisDetail `json:"images_analysis,omitempty"`   // 所有图片的分析详情
+	TimeValidation   *TimeValidationResult `json:"time_validation,omitempty"`   // 时间验证结果
+	RawText          string                `json:"raw_text,omitempty"`          // 调试文本
+	RuleTrace        []RuleTraceEntry      `json:"rule_trace,omitempty"`        // 规则评估追踪
+	LlmDisagreements []LlmDisagreement     `json:"llm_disagreements,omitempty"` // 确定性规则与 LLM 结论的分歧
+	LowConfidence    bool                  `json:"low_confidence,omitempty"`    // 通过依据仅落在时间容差窗口内，建议人工复核
+	DecidedBy        string                `json:"decided_by,omitempty"`        // 裁决层: llm, rules, llm+rules
+	Verdict          string                `json:"verdict"`                     // 结论: approve, reject, needs_review
+	ReviewReasons    []string              `json:"review_reasons,omitempty"`    // 需人工复核的原因
+	WorkingDays      int                   `json:"working_days,omitempty"`      // 多日申请范围内的工作日天数
+	History          *HistorySummary       `json:"history,omitempty"`           // 申请人近期申请记录摘要
+}
+
+// 裁决结论（AnalysisResult.Verdict）
+const (
+	VerdictApprove     = "approve"      // 通过
+	VerdictReject      = "reject"       // 驳回
+	VerdictNeedsReview = "needs_review" // 需人工复核（低置信度、规则与 LLM 分歧、部分图片失败、疑似编辑等）
+)
+
+// HistoryRecord 员工的一次历史申请（用于申请频次核对与近期记录摘要）
+type HistoryRecord struct {
+	UserId          string    `json:"user_id"`
+	ApplicationType string    `json:"application_type"`
+	Date            string    `json:"date"`                 // 申请日期 yyyy-MM-dd（多日申请为开始日期）
+	EndDate         string    `json:"end_date,omitempty"`   // 多日申请的结束日期
+	StartTime       string    `json:"start_time,omitempty"` // 申请的上班时间
+	EndTime         string    `json:"end_time,omitempty"`   // 申请的下班时间
+	Verdict         string    `json:"verdict"`              // 当时的裁决结论
+	RecordedAt      time.Time `json:"recorded_at"`          // 记录时间
+}
+
+// HistorySummary 申请人近期申请记录摘要（供审批人参考），次数不含被驳回的申请
+type HistorySummary struct {
+	MonthCounts  map[string]int  `json:"month_counts"`  // 申请日期所在自然月内按申请类型统计的次数
+	WindowDays   int             `json:"window_days"`   // 近期统计范围：申请日期前 N 天（含当天）
+	RecentCounts map[string]int  `json:"recent_counts"` // 近期按申请类型统计的次数
+	Recent       []HistoryRecord `json:"recent"`        // 最近的申请记录（由新到旧）
+}
+
+// LlmDisagreement 确定性日期/时间规则与 LLM 匹配标记不一致的记录
+type LlmDisagreement struct {
+	ImageIndex int    `json:"image_index"` // 图片索引
+	Field      string `json:"field"`       // date 或 time
+	LlmMatch   bool   `json:"llm_match"`   // LLM 的 date_match / time_match
+	RuleMatch  bool   `json:"rule_match"`  // 规则核对结果
+	Overridden bool   `json:"overridden"`  // 是否以规则结论推翻了 LLM 的通过
+	Message    string `json:"message"`     // 说明
 }
 
-// oa的考勤数据
-type OaAttendanceData struct {
-	Status          string `json:"status"`            // 例如: "正常", "迟到", "早退", "缺卡", "请假中"
-	ClockInTime     string `json:"clock_in_time"`     // 打卡上班时间 (HH:mm), "" 表示未打卡
-	ClockOutTime    string `json:"clock_out_time"`    // 打卡下班时间 (HH:mm), "" 表示未打卡
-	StandardInTime  string `json:"standard_in_time"`  // OA 系统定义的标准上班时间 (HH:mm), e.g., "09:00"
-	StandardOutTime string `json:"standard_out_time"` // OA 系统定义的标准下班时间 (HH:mm), e.g., "18:00"
+// RuleTraceEntry 单条规则的评估记录
+type RuleTraceEntry struct {
+	RuleID     string            `json:"rule_id"`               // 规则ID
+	ImageIndex int               `json:"image_index,omitempty"` // 图片级规则对应的图片索引（申请级规则为空）
+	Outcome    string            `json:"outcome"`               // 评估结果: pass, fail, skip
+	Inputs     map[string]string `json:"inputs,omitempty"`      // 规则使用的输入
+	Message    string            `json:"message,omitempty"`     // 说明（不通过/跳过原因）
+	Advisory   bool              `json:"advisory,omitempty"`    // 仅记录，不影响裁决（rules_veto 策略下的非否决规则）
 }
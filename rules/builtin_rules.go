@@ -0,0 +1,579 @@
+package rules
+
+import (
+	"fmt"
+	"log"
+	"my-ai-app/schedule"
+	"strings"
+	"time"
+)
+
+// 内置规则ID
+const (
+	RuleRequireApplicationTime = "require_application_time" // 必须提供申请时间
+	RuleExistingPunch          = "existing_punch"           // 已有打卡则无需补卡
+	RuleRequireImages          = "require_images"           // 必须提供（可用的）证明材料
+	RuleTamper                 = "tamper"                   // 图片篡改风险
+	RuleNameMatch              = "name_match"               // 证明材料姓名与申请人一致
+	RuleOcrEvidence            = "ocr_evidence"             // OCR 日期/时间核对
+	RuleDateMatch              = "date_match"               // 证明材料日期与申请日期一致
+	RuleTimeMatch              = "time_match"               // 证明材料时间满足上班 <= 开始 / 下班 >= 结束
+	RuleTypeMatch              = "type_match"               // 证明材料类型与申请类型一致
+	RuleRequiredFields         = "required_fields"          // 规则包要求的必填字段
+	RuleEvidenceType           = "evidence_type"            // 证明材料类型属于规则包可接受的类型
+	RuleEvidenceDate           = "evidence_date"            // 证明材料日期在规则包允许的范围内
+	RuleDateRange              = "date_range"               // 多日申请的日期范围有效且包含工作日
+	RuleRangeCoverage          = "range_coverage"           // 证明材料覆盖多日申请的日期范围
+	RuleShiftTime              = "shift_time"               // 补打卡时间在申请人当天班次的准时范围内
+	RulePunchPattern           = "punch_pattern"            // 补打卡时间紧邻已有打卡（疑似将迟到/早退改为补卡）
+	RuleFrequency              = "frequency"                // 同类型申请次数不超过规则包的上限
+	RuleLlmVerdict             = "llm_verdict"              // LLM 对图片的判定（由裁决策略控制，不在规则集中配置）
+)
+
+func init() {
+	Register(requireApplicationTimeRule{})
+	Register(existingPunchRule{})
+	Register(requireImagesRule{})
+	Register(tamperRule{})
+	Register(nameMatchRule{})
+	Register(ocrEvidenceRule{})
+	Register(dateMatchRule{})
+	Register(timeMatchRule{})
+	Register(typeMatchRule{})
+	Register(requiredFieldsRule{})
+	Register(evidenceTypeRule{})
+	Register(evidenceDateRule{})
+	Register(dateRangeRule{})
+	Register(rangeCoverageRule{})
+	Register(shiftTimeRule{})
+	Register(punchPatternRule{})
+	Register(frequencyRule{})
+}
+
+// requireApplicationTimeRule 未提供申请时间（上下班时间或单个时间）时驳回
+type requireApplicationTimeRule struct{}
+
+func (requireApplicationTimeRule) ID() string              { return RuleRequireApplicationTime }
+func (requireApplicationTimeRule) Scope() Scope            { return ScopeApplication }
+func (requireApplicationTimeRule) AppliesTo(_ string) bool { return true }
+func (requireApplicationTimeRule) Veto() bool              { return true }
+func (requireApplicationTimeRule) Evaluate(ctx *Context) Result {
+	app := ctx.App
+	inputs := []string{"start_time", app.StartTime, "end_time", app.EndTime, "application_time", app.ApplicationTime}
+	switch {
+	case app.StartTime == "" && app.EndTime == "" && app.ApplicationTime == "" && (app.StartDate != "" || app.EndDate != ""):
+		return Skip("多日申请按日期范围核对").With("start_date", app.StartDate, "end_date", app.EndDate)
+	case app.StartTime != "" && app.EndTime != "":
+		log.Printf("检测到上下班卡同时申请 - 上班时间: %s, 下班时间: %s", app.StartTime, app.EndTime)
+	case app.StartTime != "":
+		log.Printf("检测到上班卡申请 - 上班时间: %s", app.StartTime)
+	case app.EndTime != "":
+		log.Printf("检测到下班卡申请 - 下班时间: %s", app.EndTime)
+	case app.ApplicationTime != "":
+		// 向后兼容：单个时间申请
+		log.Printf("检测到单个时间申请 - 申请时间: %s", app.ApplicationTime)
+	default:
+		return Fail("未提供申请时间").With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
+
+// existingPunchRule 补打卡：当天已有覆盖申请时间的打卡记录（申请附带或 OA 考勤）则无需补卡
+// - 上班卡：存在 <= 上班时间的打卡，或（申请时间在班次上班时间之后时）按班次未迟到的打卡
+// - 下班卡：存在 >= 下班时间的打卡；加班到次日的下班卡不因当天已有正常下班打卡而驳回
+// 同时申请上下班卡时逐个核对，任一已被覆盖即驳回；按首末次打卡判断的当天缺卡情况写入规则输入
+// 打卡时间按申请日期与班次换算后比较，次日凌晨的下班打卡晚于当天的下班时间
+type existingPunchRule struct{}
+
+func (existingPunchRule) ID() string                    { return RuleExistingPunch }
+func (existingPunchRule) Scope() Scope                  { return ScopeApplication }
+func (existingPunchRule) AppliesTo(appType string) bool { return appType == "补打卡" }
+func (existingPunchRule) Veto() bool                    { return true }
+func (existingPunchRule) Evaluate(ctx *Context) Result {
+	app, shift, clock := ctx.App, ctx.Shift, punchClockFor(ctx)
+	if len(attendancePunches(ctx)) == 0 {
+		return Skip("未提供当天打卡记录")
+	}
+	punches := punchListFor(ctx, clock)
+	startMin, hasStart := clock.minutes(app.StartTime)
+	endMin, hasEnd := clock.minutes(app.EndTime)
+	missing := punches.missingSlots(shift)
+	inputs := []string{"start_time", app.StartTime, "end_time", app.EndTime, "attendance_info", punches.String(),
+		"missing_slots", strings.Join(missing, ","), "shift", shift.Describe()}
+	if (!hasStart && !hasEnd) || len(punches) == 0 {
+		return Skip("无可比较的申请时间或打卡记录").With(inputs...)
+	}
+
+	var startPunch, endPunch int
+	startCovered, endCovered := false, false
+	if hasStart {
+		startPunch, startCovered = punches.coveringStart(startMin, shift)
+	}
+	if hasEnd {
+		endPunch, endCovered = punches.coveringEnd(endMin)
+	}
+	switch {
+	case startCovered && endCovered:
+		return Fail("上下班均已有打卡记录（%s、%s），无需补卡", schedule.FormatMinutes(startPunch), schedule.FormatMinutes(endPunch)).With(inputs...)
+	case startCovered:
+		return Fail("已有上班打卡记录%s，无需补上班卡%s", schedule.FormatMinutes(startPunch), missingNote(missing)).With(inputs...)
+	case endCovered:
+		return Fail("已有下班打卡记录%s，无需补下班卡%s", schedule.FormatMinutes(endPunch), missingNote(missing)).With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
+
+// missingNote 驳回原因中的缺卡提示，如 "（当天缺下班卡）"
+func missingNote(missing []string) string {
+	if len(missing) == 0 {
+		return ""
+	}
+	return "（当天缺" + strings.Join(missing, "、") + "）"
+}
+
+// punchPatternRule 补打卡：已有打卡紧邻申请时间（如申请 09:00 上班卡而 09:01 已有打卡，或申请 18:00 下班卡而 17:58 已有打卡），
+// 疑似将迟到/早退改为补卡。默认非硬性规则，rules_veto 下转人工复核
+type punchPatternRule struct{}
+
+func (punchPatternRule) ID() string                    { return RulePunchPattern }
+func (punchPatternRule) Scope() Scope                  { return ScopeApplication }
+func (punchPatternRule) AppliesTo(appType string) bool { return appType == "补打卡" }
+func (punchPatternRule) Evaluate(ctx *Context) Result {
+	app, clock := ctx.App, punchClockFor(ctx)
+	punches := punchListFor(ctx, clock)
+	inputs := []string{"start_time", app.StartTime, "end_time", app.EndTime, "attendance_info", punches.String(),
+		"window_minutes", fmt.Sprintf("%d", SuspiciousPunchMinutes)}
+	if len(punches) == 0 {
+		return Skip("未提供当天打卡记录").With(inputs...)
+	}
+	if startMin, ok := clock.minutes(app.StartTime); ok {
+		if m, ok := punches.after(startMin, SuspiciousPunchMinutes); ok {
+			return Fail("补卡上班时间%s，而%s已有打卡（晚 %d 分钟），疑似将迟到改为补卡",
+				schedule.FormatMinutes(startMin), schedule.FormatMinutes(m), m-startMin).With(inputs...)
+		}
+	}
+	if endMin, ok := clock.minutes(app.EndTime); ok {
+		if m, ok := punches.before(endMin, SuspiciousPunchMinutes); ok {
+			return Fail("补卡下班时间%s，而%s已有打卡（早 %d 分钟），疑似将早退改为补卡",
+				schedule.FormatMinutes(endMin), schedule.FormatMinutes(m), endMin-m).With(inputs...)
+		}
+	}
+	return Pass().With(inputs...)
+}
+
+// attendancePunches 当天的打卡记录：申请中附带的 attendance_info 与 OA 考勤中的打卡
+func attendancePunches(ctx *Context) []string {
+	return append(append([]string(nil), ctx.App.AttendanceInfo...), ctx.Attendance.AllPunches()...)
+}
+
+// requireImagesRule 病假、补打卡及规则包要求证明材料的类型，需要图片核验时必须至少有一张分析成功的图片
+type requireImagesRule struct{}
+
+func (requireImagesRule) ID() string              { return RuleRequireImages }
+func (requireImagesRule) Scope() Scope            { return ScopeApplication }
+func (requireImagesRule) AppliesTo(_ string) bool { return true }
+func (requireImagesRule) Veto() bool              { return true }
+func (requireImagesRule) Evaluate(ctx *Context) Result {
+	if !evidenceRequired(ctx) {
+		return Skip("该申请类型无需证明材料")
+	}
+	if ctx.App.NeedImageValidation != nil && !*ctx.App.NeedImageValidation {
+		return Skip("无需图片核验")
+	}
+	inputs := []string{"analyzed_images", fmt.Sprintf("%d", len(ctx.Images)), "image_urls", fmt.Sprintf("%d", len(ctx.App.ImageUrls))}
+	if len(ctx.Images) > 0 {
+		return Pass().With(inputs...)
+	}
+	if len(ctx.App.ImageUrls) == 0 {
+		return Fail("缺少必要的证明材料图片 (未提供 image_urls)").With(inputs...)
+	}
+	return Fail("所有图片均处理失败，无法验证").With(inputs...)
+}
+
+// tamperRule 篡改风险分达到 TamperRejectScore 且命中元数据/编辑软件痕迹的图片不予采信
+// 仅有压缩痕迹（二次压缩、网格错位、ELA 异常）的图片多为转发时重压缩、裁剪的截图，不否决，由风险分转人工复核
+type tamperRule struct{}
+
+func (tamperRule) ID() string              { return RuleTamper }
+func (tamperRule) Scope() Scope            { return ScopeImage }
+func (tamperRule) AppliesTo(_ string) bool { return true }
+func (tamperRule) Veto() bool              { return true }
+func (tamperRule) Evaluate(ctx *Context) Result {
+	score := fmt.Sprintf("%.2f", ctx.Image.TamperRiskScore)
+	inputs := []string{"tamper_risk_score", score, "edit_marker", fmt.Sprintf("%t", ctx.Image.TamperEditMarker)}
+	if ctx.Image.TamperRiskScore >= TamperRejectScore && ctx.Image.TamperEditMarker {
+		return Fail("图片疑似经过编辑篡改，风险分 %s", score).With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
+
+// nameMatchRule 证明材料姓名需与申请人一致（繁简、脱敏、拼音等按 MatchName 比较，相似度写入追踪）
+type nameMatchRule struct{}
+
+func (nameMatchRule) ID() string                    { return RuleNameMatch }
+func (nameMatchRule) Scope() Scope                  { return ScopeImage }
+func (nameMatchRule) AppliesTo(appType string) bool { return appType == "病假" }
+func (nameMatchRule) Evaluate(ctx *Context) Result {
+	alias, extracted := ctx.App.Alias, ctx.Image.ExtractedName
+	inputs := []string{"alias", alias, "extracted_name", extracted}
+	switch {
+	case alias == "":
+		return Fail("%s申请未提供申请人姓名", ctx.App.ApplicationType).With(inputs...)
+	case extracted == "未知" || extracted == "":
+		return Fail("证明材料未体现申请人姓名").With(inputs...)
+	}
+	match := MatchName(alias, extracted)
+	inputs = append(inputs, "similarity", fmt.Sprintf("%.2f", match.Score), "match_method", match.Method)
+	if match.Score < NameMatchThreshold {
+		return Fail("证明材料姓名[%s]与申请人[%s]不符（相似度 %.2f）", extracted, alias, match.Score).With(inputs...)
+	}
+	log.Printf("姓名验证通过（%s，相似度 %.2f）", match.Method, match.Score)
+	return Pass().With(inputs...)
+}
+
+// ocrEvidenceRule 使用 OCR 预识别的日期/时间核对申请（OCR 未识别到时跳过）
+type ocrEvidenceRule struct{}
+
+func (ocrEvidenceRule) ID() string                    { return RuleOcrEvidence }
+func (ocrEvidenceRule) Scope() Scope                  { return ScopeImage }
+func (ocrEvidenceRule) AppliesTo(appType string) bool { return appType == "补打卡" }
+func (ocrEvidenceRule) Veto() bool                    { return true }
+func (ocrEvidenceRule) Evaluate(ctx *Context) Result {
+	if len(ctx.Image.OcrDates) == 0 && len(ctx.Image.OcrTimes) == 0 {
+		return Skip("OCR 未识别到日期或时间")
+	}
+	inputs := []string{"ocr_dates", strings.Join(ctx.Image.OcrDates, ","), "ocr_times", strings.Join(ctx.Image.OcrTimes, ","),
+		"application_date", ctx.App.ApplicationDate, "start_time", ctx.App.StartTime, "end_time", ctx.App.EndTime}
+	if reason := checkOcrEvidence(ctx); reason != "" {
+		return Fail("%s", reason).With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
+
+// dateMatchRule 证明材料日期需与申请日期一致，跨零点下班的补卡也可为次日（仅有月日时按申请日期推断年份；图片未体现日期时跳过）
+type dateMatchRule struct{}
+
+func (dateMatchRule) ID() string                    { return RuleDateMatch }
+func (dateMatchRule) Scope() Scope                  { return ScopeImage }
+func (dateMatchRule) AppliesTo(appType string) bool { return appType == "补打卡" }
+func (dateMatchRule) Veto() bool                    { return true }
+func (dateMatchRule) Evaluate(ctx *Context) Result {
+	ref, ok := parseApplicationDate(ctx.App.ApplicationDate)
+	if !ok {
+		return Skip("未提供有效的申请日期").With("application_date", ctx.App.ApplicationDate)
+	}
+	requestDate := strings.TrimSpace(ctx.Image.RequestDate)
+	inputs := []string{"request_date", requestDate, "application_date", ctx.App.ApplicationDate}
+	resolved, ok := resolveDate(requestDate, ref)
+	if !ok {
+		return Skip("证明材料未体现日期，无法核对").With(inputs...)
+	}
+	inputs = append(inputs, "resolved_date", formatDate(resolved))
+	if accepted := punchDates(ctx, ref); !matchesAnyDate(resolved, accepted) {
+		if len(accepted) > 1 {
+			return Fail("证明材料日期[%s]与申请日期[%s]及次日均不符", formatDate(resolved), formatDate(ref)).With(inputs...)
+		}
+		return Fail("证明材料日期[%s]与申请日期[%s]不符", formatDate(resolved), formatDate(ref)).With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
+
+// timeMatchRule 补打卡时间核对：上班卡需存在 <= 上班时间的图片时间，下班卡需存在 >= 下班时间的图片时间
+// 按申请类型/部门配置的容差窗口放宽或收紧；图片时间取 LLM 提取的 candidate_times、request_time、time_from_content，均未提取到时跳过
+type timeMatchRule struct{}
+
+func (timeMatchRule) ID() string                    { return RuleTimeMatch }
+func (timeMatchRule) Scope() Scope                  { return ScopeImage }
+func (timeMatchRule) AppliesTo(appType string) bool { return appType == "补打卡" }
+func (timeMatchRule) Veto() bool                    { return true }
+func (timeMatchRule) Evaluate(ctx *Context) Result {
+	times := imageTimes(punchClockFor(ctx), ctx.Image.CandidateTimes, ctx.Image.RequestTime, ctx.Image.TimeFromContent)
+	startTime, endTime := applicationPunchTimes(ctx.App)
+	inputs := []string{"image_times", strings.Join(times, ","), "start_time", startTime, "end_time", endTime,
+		"grace_minutes", fmt.Sprintf("%d", ctx.Tolerance.GraceMinutes),
+		"max_distance_minutes", fmt.Sprintf("%d", ctx.Tolerance.MaxDistanceMinutes)}
+	if len(times) == 0 {
+		return Skip("证明材料未体现时间，无法核对").With(inputs...)
+	}
+	if _, ok := clockMinutes(startTime); !ok {
+		if _, ok := clockMinutes(endTime); !ok {
+			return Skip("未提供申请时间").With(inputs...)
+		}
+	}
+
+	match := matchPunchTimes(times, startTime, endTime, ctx.Tolerance, punchClockFor(ctx), "证明材料")
+	if !match.ok {
+		return Fail("%s", match.reason).With(inputs...)
+	}
+	if match.lowConfidence {
+		res := Pass().With(inputs...)
+		res.Reason = fmt.Sprintf("证明材料时间在 %d 分钟容差窗口内，低置信度", ctx.Tolerance.GraceMinutes)
+		res.LowConfidence = true
+		return res
+	}
+	return Pass().With(inputs...)
+}
+
+// typeMatchRule 证明材料类型需与申请类型一致（双向包含即视为一致）
+type typeMatchRule struct{}
+
+func (typeMatchRule) ID() string              { return RuleTypeMatch }
+func (typeMatchRule) Scope() Scope            { return ScopeImage }
+func (typeMatchRule) AppliesTo(_ string) bool { return true }
+func (typeMatchRule) Evaluate(ctx *Context) Result {
+	requestType := strings.TrimSpace(ctx.Image.RequestType)
+	appType := ctx.App.ApplicationType
+	inputs := []string{"request_type", requestType, "application_type", appType}
+	if requestType == "" || requestType == "未知" {
+		return Fail("证明材料未体现申请类型").With(inputs...)
+	}
+	if !strings.Contains(requestType, appType) && !strings.Contains(appType, requestType) {
+		return Fail("证明材料类型[%s]与申请类型[%s]不符", requestType, appType).With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
+
+// llmVerdictRule 图片需被 LLM 判定为通过（裁决策略中的 LLM 层）
+type llmVerdictRule struct{}
+
+func (llmVerdictRule) ID() string              { return RuleLlmVerdict }
+func (llmVerdictRule) Scope() Scope            { return ScopeImage }
+func (llmVerdictRule) AppliesTo(_ string) bool { return true }
+func (llmVerdictRule) Evaluate(ctx *Context) Result {
+	inputs := []string{"approve", fmt.Sprintf("%v", ctx.Image.Approve), "is_valid", fmt.Sprintf("%v", ctx.Image.IsValid)}
+	if ctx.Image.Approve || ctx.Image.IsValid {
+		return Pass().With(inputs...)
+	}
+	if reason := strings.TrimSpace(ctx.Image.ReasonLLM); reason != "" {
+		return Fail("%s", reason).With(inputs...)
+	}
+	return Fail("LLM判定：不通过").With(inputs...)
+}
+
+// requiredFieldsRule 规则包要求的必填字段不能为空
+type requiredFieldsRule struct{}
+
+func (requiredFieldsRule) ID() string              { return RuleRequiredFields }
+func (requiredFieldsRule) Scope() Scope            { return ScopeApplication }
+func (requiredFieldsRule) AppliesTo(_ string) bool { return true }
+func (requiredFieldsRule) Veto() bool              { return true }
+func (requiredFieldsRule) Evaluate(ctx *Context) Result {
+	if len(ctx.Pack.RequiredFields) == 0 {
+		return Skip("未配置必填字段")
+	}
+	var missing []string
+	for _, field := range ctx.Pack.RequiredFields {
+		if strings.TrimSpace(fieldValue(ctx, field)) == "" {
+			label := requiredFieldLabels[field]
+			if label == "" {
+				label = field
+			}
+			missing = append(missing, label)
+		}
+	}
+	inputs := []string{"required_fields", strings.Join(ctx.Pack.RequiredFields, ","), "missing", strings.Join(missing, ",")}
+	if len(missing) > 0 {
+		return Fail("%s申请缺少必填信息: %s", ctx.App.ApplicationType, strings.Join(missing, "、")).With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
+
+// evidenceTypeRule 证明材料类型需包含规则包可接受的类型关键词（规则包未限定类型时跳过）
+type evidenceTypeRule struct{}
+
+func (evidenceTypeRule) ID() string              { return RuleEvidenceType }
+func (evidenceTypeRule) Scope() Scope            { return ScopeImage }
+func (evidenceTypeRule) AppliesTo(_ string) bool { return true }
+func (evidenceTypeRule) Evaluate(ctx *Context) Result {
+	accepted := ctx.Pack.EvidenceTypes
+	if len(accepted) == 0 {
+		return Skip("未限定证明材料类型")
+	}
+	inputs := []string{"proof_type", ctx.Image.ProofType, "accepted_types", strings.Join(accepted, ",")}
+	text := evidenceText(ctx.Image)
+	for _, t := range accepted {
+		if strings.Contains(text, t) {
+			return Pass().With(append(inputs, "matched_type", t)...)
+		}
+	}
+	proofType := ctx.Image.ProofType
+	if proofType == "" {
+		proofType = "未知"
+	}
+	return Fail("证明材料类型[%s]不是%s可接受的材料（%s）", proofType, ctx.App.ApplicationType, strings.Join(accepted, "、")).With(inputs...)
+}
+
+// evidenceDateRule 证明材料日期需在规则包允许的范围内（未配置范围、缺少申请日期或图片未体现日期时跳过）
+type evidenceDateRule struct{}
+
+func (evidenceDateRule) ID() string              { return RuleEvidenceDate }
+func (evidenceDateRule) Scope() Scope            { return ScopeImage }
+func (evidenceDateRule) AppliesTo(_ string) bool { return true }
+func (evidenceDateRule) Veto() bool              { return true }
+func (evidenceDateRule) Evaluate(ctx *Context) Result {
+	window := ctx.Pack.DateWindow
+	if window == nil {
+		return Skip("未配置证明材料日期范围")
+	}
+	start, end, ok := applicationRange(ctx.App)
+	if !ok {
+		return Skip("未提供有效的申请日期").With("application_date", ctx.App.ApplicationDate)
+	}
+	requestDate := strings.TrimSpace(ctx.Image.RequestDate)
+	inputs := []string{"request_date", requestDate, "application_range", formatRange(start, end),
+		"days_before", fmt.Sprintf("%d", window.DaysBefore), "days_after", fmt.Sprintf("%d", window.DaysAfter)}
+	resolved, ok := resolveDate(requestDate, start)
+	if !ok {
+		return Skip("证明材料未体现日期，无法核对").With(inputs...)
+	}
+	inputs = append(inputs, "resolved_date", formatDate(resolved))
+	if resolved.Before(start.AddDate(0, 0, -window.DaysBefore)) || resolved.After(end.AddDate(0, 0, window.DaysAfter)) {
+		return Fail("证明材料日期[%s]不在%s", formatDate(resolved), describeWindow(*window)).With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
+
+// maxRangeDays 多日申请允许的最大天数
+const maxRangeDays = 366
+
+// dateRangeRule 多日申请（提供了 start_date/end_date）的日期需有效、结束不早于开始；请假类申请范围内需至少有一个工作日
+type dateRangeRule struct{}
+
+func (dateRangeRule) ID() string              { return RuleDateRange }
+func (dateRangeRule) Scope() Scope            { return ScopeApplication }
+func (dateRangeRule) AppliesTo(_ string) bool { return true }
+func (dateRangeRule) Veto() bool              { return true }
+func (dateRangeRule) Evaluate(ctx *Context) Result {
+	app := ctx.App
+	if app.StartDate == "" && app.EndDate == "" {
+		return Skip("单日申请")
+	}
+	inputs := []string{"start_date", app.StartDate, "end_date", app.EndDate}
+	for _, d := range []string{app.StartDate, app.EndDate} {
+		if _, ok := parseApplicationDate(d); d != "" && !ok {
+			return Fail("日期[%s]格式无效", d).With(inputs...)
+		}
+	}
+	start, end, _ := applicationRange(app)
+	if end.Before(start) {
+		return Fail("结束日期%s早于开始日期%s", formatDate(end), formatDate(start)).With(inputs...)
+	}
+	days := int(end.Sub(start).Hours()/24+0.5) + 1
+	workingDays := countWorkingDays(start, end)
+	inputs = append(inputs, "days", fmt.Sprintf("%d", days), "working_days", fmt.Sprintf("%d", workingDays))
+	if days > maxRangeDays {
+		return Fail("申请跨度 %d 天，超过上限 %d 天", days, maxRangeDays).With(inputs...)
+	}
+	if isLeaveType(app.ApplicationType) && workingDays == 0 {
+		return Fail("申请日期范围%s内没有工作日", formatRange(start, end)).With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
+
+// isLeaveType 是否为请假类申请（占用工作日）
+func isLeaveType(appType string) bool {
+	return strings.HasSuffix(appType, "假") || appType == "调休"
+}
+
+// rangeCoverageRule 多日的病假、出差、外出申请，证明材料覆盖的日期范围需包含整个申请范围
+// 证明材料范围取 LLM 提取的 evidence_start_date/evidence_end_date，未提取到时以 request_date 作为单日范围
+type rangeCoverageRule struct{}
+
+func (rangeCoverageRule) ID() string   { return RuleRangeCoverage }
+func (rangeCoverageRule) Scope() Scope { return ScopeImage }
+func (rangeCoverageRule) AppliesTo(appType string) bool {
+	return appType == "病假" || appType == "出差" || appType == "外出"
+}
+func (rangeCoverageRule) Veto() bool { return true }
+func (rangeCoverageRule) Evaluate(ctx *Context) Result {
+	start, end, ok := applicationRange(ctx.App)
+	if !ok || !end.After(start) {
+		return Skip("单日申请")
+	}
+	d := ctx.Image
+	inputs := []string{"application_range", formatRange(start, end), "evidence_start_date", d.EvidenceStartDate,
+		"evidence_end_date", d.EvidenceEndDate, "request_date", d.RequestDate}
+	evStart, okStart := resolveDate(d.EvidenceStartDate, start)
+	evEnd, okEnd := resolveDate(d.EvidenceEndDate, end)
+	switch {
+	case okStart && okEnd:
+	case okStart:
+		evEnd = evStart
+	case okEnd:
+		evStart = evEnd
+	default:
+		day, ok := resolveDate(d.RequestDate, start)
+		if !ok {
+			return Skip("证明材料未体现日期，无法核对").With(inputs...)
+		}
+		evStart, evEnd = day, day
+	}
+	inputs = append(inputs, "evidence_range", formatRange(evStart, evEnd))
+	if evStart.After(start) || evEnd.Before(end) {
+		return Fail("证明材料日期[%s]未覆盖申请日期范围[%s]", formatRange(evStart, evEnd), formatRange(start, end)).With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
+
+// shiftTimeRule 补打卡：申请补卡的时间需在申请人当天班次的准时范围内（上班卡不晚于最晚上班时间，下班卡不早于最早下班时间）
+// 补卡后仍为迟到/早退的申请不宜直接通过，默认非硬性规则，rules_veto 下转人工复核
+type shiftTimeRule struct{}
+
+func (shiftTimeRule) ID() string                    { return RuleShiftTime }
+func (shiftTimeRule) Scope() Scope                  { return ScopeApplication }
+func (shiftTimeRule) AppliesTo(appType string) bool { return appType == "补打卡" }
+func (shiftTimeRule) Evaluate(ctx *Context) Result {
+	app, shift, clock := ctx.App, ctx.Shift, punchClockFor(ctx)
+	startTime, endTime := applicationPunchTimes(app)
+	inputs := []string{"shift", shift.Describe(), "start_time", startTime, "end_time", endTime}
+	startMin, hasStart := clock.minutes(startTime)
+	endMin, hasEnd := clock.minutes(endTime)
+	if !hasStart && !hasEnd {
+		return Skip("未提供申请时间").With(inputs...)
+	}
+	if hasStart && startMin > shift.LatestStartMinutes() {
+		return Fail("补卡上班时间%s晚于班次[%s]最晚上班时间%s", schedule.FormatMinutes(startMin), shift.Describe(),
+			schedule.FormatMinutes(shift.LatestStartMinutes())).With(inputs...)
+	}
+	if hasEnd {
+		clockIn := -1
+		if hasStart {
+			clockIn = startMin
+		}
+		if earliest := shift.EarliestEndMinutes(clockIn); endMin < earliest {
+			return Fail("补卡下班时间%s早于班次[%s]最早下班时间%s", schedule.FormatMinutes(endMin), shift.Describe(),
+				schedule.FormatMinutes(earliest)).With(inputs...)
+		}
+	}
+	return Pass().With(inputs...)
+}
+
+// frequencyRule 规则包配置了次数上限的申请类型（内置：补打卡每月 3 次、病假 30 天内 3 次）：
+// 统计范围内同类型申请（含本次，不含被驳回的申请）超过上限时不通过。默认非硬性规则，rules_veto 下转人工复核
+type frequencyRule struct{}
+
+func (frequencyRule) ID() string              { return RuleFrequency }
+func (frequencyRule) Scope() Scope            { return ScopeApplication }
+func (frequencyRule) AppliesTo(_ string) bool { return true }
+func (frequencyRule) Evaluate(ctx *Context) Result {
+	f := ctx.Pack.Frequency
+	if f == nil || f.MaxCount <= 0 {
+		return Skip("该申请类型未配置次数上限")
+	}
+	if ctx.History == nil {
+		return Skip("未启用申请记录")
+	}
+	current, ok := HistoryRecordFor(ctx.App, "", time.Time{})
+	if !ok {
+		return Skip("缺少员工ID或申请日期无效")
+	}
+	ref, _ := parseApplicationDate(current.Date)
+	from, to := f.window(ref)
+	appType := ctx.App.ApplicationType
+	count := countByType(ctx.History, current, from, to)[appType] + 1
+	inputs := []string{"window", formatRange(from, to), "count", fmt.Sprintf("%d", count), "max_count", fmt.Sprintf("%d", f.MaxCount)}
+	if count > f.MaxCount {
+		return Fail("%s%s已申请 %d 次（含本次），超过上限 %d 次", f.describe(), appType, count, f.MaxCount).With(inputs...)
+	}
+	return Pass().With(inputs...)
+}
@@ -0,0 +1,26 @@
+package rules
+
+import (
+	"my-ai-app/model"
+	"testing"
+)
+
+func TestTamperRule(t *testing.T) {
+	tests := []struct {
+		name   string
+		score  float64
+		marker bool
+		want   Outcome
+	}{
+		{"无痕迹", 0, false, OutcomePass},
+		{"仅压缩痕迹（转发重压缩+裁剪+ELA）", 0.82, false, OutcomePass},
+		{"编辑软件且高分", 0.82, true, OutcomeFail},
+		{"编辑软件但低分", 0.5, true, OutcomePass},
+	}
+	for _, tt := range tests {
+		ctx := &Context{Image: &model.ExtractedData{TamperRiskScore: tt.score, TamperEditMarker: tt.marker}}
+		if got := (tamperRule{}).Evaluate(ctx).Outcome; got != tt.want {
+			t.Errorf("%s: outcome = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
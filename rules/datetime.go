@@ -0,0 +1,118 @@
+package rules
+
+import (
+	"fmt"
+	"my-ai-app/calendar"
+	"my-ai-app/model"
+	"my-ai-app/timeparse"
+	"my-ai-app/timezone"
+	"strings"
+	"time"
+)
+
+// resolveDate 将日期字符串解析为具体日期（支持 "昨天"、"次日" 等相对日期与中文写法，见 timeparse.ResolveDate）
+// 仅有月日时参照 ref 推断年份；ref 为零值时含年份的日期按公司时区表示
+func resolveDate(s string, ref time.Time) (time.Time, bool) {
+	day, ok := timeparse.ResolveDate(s, ref)
+	if ok && ref.IsZero() {
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, timezone.Default().Company())
+	}
+	return day, ok
+}
+
+// parseApplicationDate 解析申请日期（yyyy-MM-dd 等含年份的写法），日期按公司时区表示
+func parseApplicationDate(s string) (time.Time, bool) {
+	year, month, day, ok := timeparse.DateParts(s)
+	if !ok || year == 0 {
+		return time.Time{}, false
+	}
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, timezone.Default().Company()), true
+}
+
+// applicationRange 返回申请的日期范围：提供了 start_date/end_date 时取其范围（只提供一个时视为单日），否则为申请日期当天
+func applicationRange(app model.ApplicationData) (start, end time.Time, ok bool) {
+	start, okStart := parseApplicationDate(app.StartDate)
+	end, okEnd := parseApplicationDate(app.EndDate)
+	switch {
+	case okStart && okEnd:
+		return start, end, true
+	case okStart:
+		return start, start, true
+	case okEnd:
+		return end, end, true
+	}
+	day, ok := parseApplicationDate(app.ApplicationDate)
+	return day, day, ok
+}
+
+// formatRange 格式化日期范围（单日时只显示一天）
+func formatRange(start, end time.Time) string {
+	if start.Equal(end) {
+		return formatDate(start)
+	}
+	return formatDate(start) + "~" + formatDate(end)
+}
+
+// countWorkingDays 统计日期范围内（含首尾）的工作日天数（按节假日日历，含调休上班日）
+func countWorkingDays(start, end time.Time) int {
+	return calendar.Default().WorkingDays(start, end)
+}
+
+// WorkingDays 返回多日申请（提供了 start_date/end_date）范围内的工作日天数
+func WorkingDays(app model.ApplicationData) (int, bool) {
+	if app.StartDate == "" && app.EndDate == "" {
+		return 0, false
+	}
+	start, end, ok := applicationRange(app)
+	if !ok || end.Before(start) {
+		return 0, false
+	}
+	return countWorkingDays(start, end), true
+}
+
+// punchDates 补打卡证据可接受的日期：申请日期，申请的打卡时间落在次日（跨零点下班）时还包括次日
+func punchDates(ctx *Context, ref time.Time) []time.Time {
+	clock := punchClockFor(ctx)
+	startTime, endTime := applicationPunchTimes(ctx.App)
+	for _, t := range []string{startTime, endTime} {
+		if m, ok := clock.minutes(t); ok && m >= 24*60 {
+			return []time.Time{ref, ref.AddDate(0, 0, 1)}
+		}
+	}
+	return []time.Time{ref}
+}
+
+// matchesAnyDate 日期是否为候选日期之一
+func matchesAnyDate(d time.Time, candidates []time.Time) bool {
+	for _, c := range candidates {
+		if d.Equal(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDate 格式化为 yyyy-MM-dd
+func formatDate(t time.Time) string {
+	return fmt.Sprintf("%04d-%02d-%02d", t.Year(), t.Month(), t.Day())
+}
+
+// imageTimes 汇总 LLM 从图片中提取的时间点（按 clock 换算后的 HH:mm，去重）
+func imageTimes(clock punchClock, candidates []string, requestTime, timeFromContent string) []string {
+	var out []string
+	seen := map[string]bool{}
+	add := func(raw string) {
+		for _, part := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '，' || r == '、' || r == ';' }) {
+			if nt, ok := clock.normalize(strings.TrimSpace(part)); ok && !seen[nt] {
+				seen[nt] = true
+				out = append(out, nt)
+			}
+		}
+	}
+	for _, c := range candidates {
+		add(c)
+	}
+	add(requestTime)
+	add(timeFromContent)
+	return out
+}
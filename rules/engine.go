@@ -4,245 +4,399 @@ import (
 	"fmt"
 	"log"
 	"my-ai-app/model"
-	"regexp"
+	"my-ai-app/schedule"
+	"my-ai-app/timeparse"
+	"my-ai-app/timezone"
 	"strings"
+	"sync"
 	"time"
 )
 
 // normalizeTimeFormat 将各种时间格式转换为 HH:mm 格式
+// 次日时间（"24:30"、"次日00:30"）保留为 24:00 之后的小时数；带日期的时间忽略日期、时区标注不换算，需按申请日期与员工时区换算时使用 normalizePunchTime
 func normalizeTimeFormat(timeStr string) (string, error) {
+	return normalizePunchTime(timeStr, time.Time{}, nil)
+}
+
+// normalizePunchTime 将打卡时间转换为相对 ref（申请日期）的 HH:mm，次日时间的小时数加 24（如 "24:30"）
+// 时间写法见 timeparse（"18：32"、"下午6点32"、"6:32 PM"、"次日00:30"、"昨天 18:32"、"2025-10-16 00:30" 等）；
+// 带日期或相对日期的时间仅接受 ref 当天或次日，ref 为零值时忽略日期（相对日期仍按当天换算）
+// loc 为员工所在时区：时间后标注了时区（如 "01:00 UTC"、"2025-10-15T01:00:00Z"）时换算到 loc，loc 为 nil 时忽略时区标注
+func normalizePunchTime(timeStr string, ref time.Time, loc *time.Location) (string, error) {
+	timeStr = strings.TrimSpace(timeStr)
 	if timeStr == "" || timeStr == "未知" {
 		return "", fmt.Errorf("时间字符串为空或未知")
 	}
-
-	// 尝试不同的时间格式
-	layouts := []string{
-		"15:04",               // HH:mm
-		"2006-01-02 15:04:05", // Y-m-d H:i:s
-		"2006-01-02 15:04",    // Y-m-d H:i
-		"2006/01/02 15:04:05", // Y/m/d H:i:s
-		"2006/01/02 15:04",    // Y/m/d H:i
-		"01-02 15:04",         // m-d H:i
-		"01/02 15:04",         // m/d H:i
+	t, ok := timeparse.ParseTime(timeStr)
+	if !ok {
+		return "", fmt.Errorf("无法解析时间格式: %s", timeStr)
 	}
+	hour, minute := t.Hour, t.Minute
 
-	for _, layout := range layouts {
-		if t, err := time.Parse(layout, timeStr); err == nil {
-			return t.Format("15:04"), nil
+	// offset 为相对申请日期的天数，日期无法推断时按当天
+	offset, _ := t.Offset(ref)
+	if zone := t.Zone; zone != nil && loc != nil {
+		base := ref
+		if base.IsZero() {
+			base = time.Now().In(loc)
 		}
+		y, m, d := base.AddDate(0, 0, offset).Date()
+		local := time.Date(y, m, d, hour, minute, 0, 0, zone).In(loc)
+		offset, hour, minute = timeparse.DaysBetween(base, local), local.Hour(), local.Minute()
 	}
+	if offset < 0 || offset > 1 {
+		return "", fmt.Errorf("时间[%s]不在申请日期当天或次日", timeStr)
+	}
+	hour += 24 * offset
+	if hour > 47 {
+		return "", fmt.Errorf("无法解析时间格式: %s", timeStr)
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute), nil
+}
 
-	// 尝试正则表达式提取时间
-	timeRegex := regexp.MustCompile(`(\d{1,2}):(\d{2})`)
-	if matches := timeRegex.FindStringSubmatch(timeStr); len(matches) >= 3 {
-		hour := matches[1]
-		minute := matches[2]
-		if len(hour) == 1 {
-			hour = "0" + hour
+// ShiftFor 申请人当天的班次：按排班配置确定，OA 考勤提供了当天标准上下班时间时以 OA 为准（弹性窗口沿用排班配置）
+func ShiftFor(app model.ApplicationData, attendance *model.AttendanceData) schedule.Shift {
+	shift := schedule.Default().ShiftForDate(app.UserId, app.Department, app.ApplicationDate)
+	if attendance != nil {
+		shift = shift.WithStandardTimes(attendance.StandardInTime, attendance.StandardOutTime)
+	}
+	return shift
+}
+
+// TamperRejectScore 篡改风险分达到该值且命中元数据/编辑软件痕迹的图片不予采信
+const TamperRejectScore = 0.7
+
+// ValidateApplication 使用内置规则配置裁决申请
+func ValidateApplication(appData model.ApplicationData, attendance *model.AttendanceData, history []model.HistoryRecord, imageList []*model.ExtractedData) *model.AnalysisResult {
+	return DefaultEngine().Validate(appData, attendance, history, imageList)
+}
+
+// Engine 规则引擎：按申请类型选择规则集，按裁决策略组合 LLM 判定与规则集
+type Engine struct {
+	policy     string
+	tolerances []ToleranceSpec
+	packs      map[string]Pack
+	defaultSet ruleSet
+	typeSets   map[string]ruleSet
+}
+
+// NewEngine 根据规则配置创建规则引擎
+func NewEngine(cfg *RuleConfig) (*Engine, error) {
+	defaultSet, err := compileRuleSet(cfg.Default)
+	if err != nil {
+		return nil, fmt.Errorf("default 规则集: %w", err)
+	}
+	e := &Engine{
+		policy:     PolicyRulesVeto,
+		tolerances: cfg.Tolerances,
+		packs:      cfg.Packs,
+		defaultSet: defaultSet,
+		typeSets:   make(map[string]ruleSet),
+	}
+	switch cfg.VerdictPolicy {
+	case "":
+	case PolicyLlmOnly, PolicyRulesOnly, PolicyLlmThenRules, PolicyRulesVeto:
+		e.policy = cfg.VerdictPolicy
+	default:
+		return nil, fmt.Errorf("未知的 verdict_policy: %s", cfg.VerdictPolicy)
+	}
+	for appType, specs := range cfg.Types {
+		set, err := compileRuleSet(specs)
+		if err != nil {
+			return nil, fmt.Errorf("%s 规则集: %w", appType, err)
 		}
-		return hour + ":" + minute, nil
+		e.typeSets[appType] = set
 	}
+	return e, nil
+}
 
-	return "", fmt.Errorf("无法解析时间格式: %s", timeStr)
+var (
+	defaultEngine     *Engine
+	defaultEngineOnce sync.Once
+)
+
+// DefaultEngine 返回使用内置规则配置的规则引擎
+func DefaultEngine() *Engine {
+	defaultEngineOnce.Do(func() {
+		engine, err := NewEngine(DefaultRuleConfig())
+		if err != nil {
+			panic(fmt.Sprintf("内置规则配置无效: %v", err))
+		}
+		defaultEngine = engine
+	})
+	return defaultEngine
 }
 
-func compareTimes(timeStr1, timeStr2 string) (bool, error) {
-	layout := "15:04"
-	t1, err1 := time.Parse(layout, timeStr1)
-	if err1 != nil {
-		return false, fmt.Errorf("无法解析时间 '%s': %w", timeStr1, err1)
+// ruleSetFor 返回申请类型对应的规则集，未单独配置时使用 default
+func (e *Engine) ruleSetFor(appType string) ruleSet {
+	if set, ok := e.typeSets[appType]; ok {
+		return set
 	}
-	t2, err2 := time.Parse(layout, timeStr2)
-	if err2 != nil {
-		return false, fmt.Errorf("无法解析时间 '%s': %w", timeStr2, err2)
+	return e.defaultSet
+}
+
+// Validate 裁决申请
+// 1. 按顺序评估申请级规则，任一（计入裁决的）规则不通过即驳回
+// 2. 逐张图片评估 LLM 层与图片级规则，任意一张图片没有不通过项即整体通过
+// 裁决策略决定参与的层：llm_only 仅 LLM 层，rules_only 仅规则层，llm_then_rules 两层都需通过，
+// rules_veto 两层都评估但规则层仅硬性规则（veto）计入裁决
+// 每条实际评估的规则都会记录到结果的 RuleTrace 中，确定性日期/时间规则与 LLM 结论不一致时记录到 LlmDisagreements，
+// 结果的 DecidedBy 标明由哪一层作出裁决
+func (e *Engine) Validate(appData model.ApplicationData, attendance *model.AttendanceData, history []model.HistoryRecord, imageList []*model.ExtractedData) *model.AnalysisResult {
+	ev := &evaluation{}
+	result := e.validate(appData, attendance, history, imageList, ev)
+	result.RuleTrace = ev.trace
+	result.LlmDisagreements = ev.disagreements
+	if days, ok := WorkingDays(appData); ok {
+		result.WorkingDays = days
 	}
+	ev.assess(result)
+	return result
+}
+
+// evaluation 单次裁决过程中收集的追踪信息
+type evaluation struct {
+	trace         []model.RuleTraceEntry
+	disagreements []model.LlmDisagreement
+	passedImage   *model.ExtractedData // 通过裁决的图片
+	passedIndex   int
+}
 
-	return t1.After(t2), nil
+// record 记录规则评估结果，advisory 表示该结果不计入裁决
+func (ev *evaluation) record(ruleID string, imageIndex int, res Result, advisory bool) {
+	ev.trace = append(ev.trace, model.RuleTraceEntry{
+		RuleID:     ruleID,
+		ImageIndex: imageIndex,
+		Outcome:    string(res.Outcome),
+		Inputs:     res.Inputs,
+		Message:    res.Reason,
+		Advisory:   advisory,
+	})
+}
+
+// llmMatchFlags 确定性规则对应的 LLM 匹配标记
+var llmMatchFlags = map[string]struct {
+	field string
+	flag  func(*model.ExtractedData) bool
+}{
+	RuleDateMatch: {"date", func(d *model.ExtractedData) bool { return d.DateMatch }},
+	RuleTimeMatch: {"time", func(d *model.ExtractedData) bool { return d.TimeMatch }},
+}
+
+// compareWithLlm 确定性规则有结论且与 LLM 的匹配标记不一致时记录分歧
+func (ev *evaluation) compareWithLlm(ruleID string, ctx *Context, res Result, overridden bool) {
+	f, ok := llmMatchFlags[ruleID]
+	if !ok || res.Outcome == OutcomeSkip {
+		return
+	}
+	ruleMatch, llmMatch := res.Outcome == OutcomePass, f.flag(ctx.Image)
+	if ruleMatch == llmMatch {
+		return
+	}
+	message := res.Reason
+	if ruleMatch {
+		message = fmt.Sprintf("规则核对%s匹配，LLM 判定不匹配", fieldLabel(f.field))
+	}
+	log.Printf("图片 %d 规则 %s 与 LLM 结论不一致: %s", ctx.ImageIndex, ruleID, message)
+	ev.disagreements = append(ev.disagreements, model.LlmDisagreement{
+		ImageIndex: ctx.ImageIndex,
+		Field:      f.field,
+		LlmMatch:   llmMatch,
+		RuleMatch:  ruleMatch,
+		Overridden: overridden,
+		Message:    message,
+	})
 }
 
-func ValidateApplication(appData model.ApplicationData, oaAttendance *model.OaAttendanceData, imageList []*model.ExtractedData) *model.AnalysisResult {
-	// LLM 裁决优先（多图规则：任意一张通过则整体通过；否则整体不通过）
-	var firstFailReason string
+// useLlm 裁决策略是否包含 LLM 层
+func (e *Engine) useLlm() bool { return e.policy != PolicyRulesOnly }
+
+// useRules 裁决策略是否包含规则层
+func (e *Engine) useRules() bool { return e.policy != PolicyLlmOnly }
+
+// decisive 规则的不通过结果是否计入裁决（rules_veto 下仅硬性规则计入）
+func (e *Engine) decisive(r compiledRule) bool { return e.policy != PolicyRulesVeto || r.veto }
+
+func (e *Engine) validate(appData model.ApplicationData, attendance *model.AttendanceData, history []model.HistoryRecord, imageList []*model.ExtractedData, ev *evaluation) *model.AnalysisResult {
+	set := e.ruleSetFor(appData.ApplicationType)
+	var images []*model.ExtractedData
 	for _, d := range imageList {
-		if d == nil {
-			continue
-		}
-		if d.Approve || d.IsValid {
-			reason := d.ReasonLLM
-			if strings.TrimSpace(reason) == "" {
-				reason = "LLM判定：通过"
-			}
-			return &model.AnalysisResult{IsAbnormal: false, Reason: reason}
-		}
-		if firstFailReason == "" && (!d.Approve || !d.IsValid) {
-			r := d.ReasonLLM
-			if strings.TrimSpace(r) == "" {
-				r = "LLM判定：不通过"
-			}
-			firstFailReason = r
+		if d != nil {
+			images = append(images, d)
 		}
 	}
-	if firstFailReason != "" {
-		return &model.AnalysisResult{IsAbnormal: true, Reason: firstFailReason}
-	}
-
-	// 确定申请的时间范围
-	var startTime, endTime string
-	if appData.StartTime != "" || appData.EndTime != "" {
-		startTime = appData.StartTime
-		endTime = appData.EndTime
-		if startTime != "" && endTime != "" {
-			log.Printf("检测到上下班卡同时申请 - 上班时间: %s, 下班时间: %s", startTime, endTime)
-		} else if startTime != "" {
-			log.Printf("检测到上班卡申请 - 上班时间: %s", startTime)
-		} else {
-			log.Printf("检测到下班卡申请 - 下班时间: %s", endTime)
-		}
-	} else if appData.ApplicationTime != "" {
-		// 向后兼容：单个时间申请
-		startTime = appData.ApplicationTime
-		endTime = ""
-		log.Printf("检测到单个时间申请 - 申请时间: %s", startTime)
-	} else {
-		return &model.AnalysisResult{IsAbnormal: true, Reason: "未提供申请时间"}
-	}
-
-	// 先进行“已有打卡则无需补卡”的快速判断
-	if appData.ApplicationType == "补打卡" && len(appData.AttendanceInfo) > 0 {
-		// 统一到 HH:mm 并去重
-		normalize := func(ts []string) []string {
-			out := make([]string, 0, len(ts))
-			seen := map[string]struct{}{}
-			for _, t := range ts {
-				if nt, err := normalizeTimeFormat(strings.TrimSpace(t)); err == nil {
-					if _, ok := seen[nt]; !ok {
-						seen[nt] = struct{}{}
-						out = append(out, nt)
-					}
-				}
-			}
-			return out
-		}
+	ctx := &Context{
+		App:        appData,
+		Attendance: attendance,
+		History:    history,
+		Images:     images,
+		Tolerance:  toleranceFor(e.tolerances, appData.ApplicationType, appData.Department),
+		Pack:       e.packs[appData.ApplicationType],
+		Shift:      ShiftFor(appData, attendance),
+		Location:   timezone.Default().For(appData.UserId, appData.Department),
+	}
+	log.Printf("裁决策略: %s", e.policy)
 
-		clockTimes := normalize(appData.AttendanceInfo)
-		var target string
-		if appData.StartTime != "" {
-			if nt, err := normalizeTimeFormat(appData.StartTime); err == nil {
-				target = nt
+	if e.useRules() {
+		for _, r := range set.application {
+			if !r.AppliesTo(appData.ApplicationType) {
+				continue
 			}
-		}
-		if appData.EndTime != "" { // 如果是下班卡，优先生效
-			if nt, err := normalizeTimeFormat(appData.EndTime); err == nil {
-				target = nt
-			}
-		}
-
-		if target != "" && len(clockTimes) > 0 {
-			// 查找最接近 target 的已有打卡点，判断是否已覆盖申请
-			// 规则：
-			// - 上班卡：存在 <= target 的打卡则认为已有打卡，无需补卡
-			// - 下班卡：存在 >= target 的打卡则认为已有打卡，无需补卡
-			isStart := appData.EndTime == ""
-			for _, ct := range clockTimes {
-				if isStart {
-					later, _ := compareTimes(ct, target)
-					if !later {
-						return &model.AnalysisResult{IsAbnormal: true, Reason: fmt.Sprintf("已有打卡记录%s，无需补卡", ct)}
-					}
-				} else {
-					earlier, _ := compareTimes(target, ct)
-					if !earlier {
-						return &model.AnalysisResult{IsAbnormal: true, Reason: fmt.Sprintf("已有打卡记录%s，无需补卡", ct)}
-					}
-				}
+			res := r.Evaluate(ctx)
+			advisory := !e.decisive(r)
+			log.Printf("规则 %s: %s %s", r.ID(), res.Outcome, res.Reason)
+			ev.record(r.ID(), 0, res, advisory)
+			if res.Outcome == OutcomeFail && !advisory {
+				return &model.AnalysisResult{IsAbnormal: true, Reason: res.Reason, DecidedBy: DecidedByRules}
 			}
 		}
 	}
 
-	// 检查是否缺少必要的图片
-	needImageValidation := true
-	if appData.NeedImageValidation != nil {
-		needImageValidation = *appData.NeedImageValidation
-	}
-	if needImageValidation && (appData.ApplicationType == "病假" || appData.ApplicationType == "补打卡") && len(imageList) == 0 {
-		if len(appData.ImageUrls) == 0 {
-			return &model.AnalysisResult{IsAbnormal: true, Reason: "缺少必要的证明材料图片 (未提供 image_urls)"}
-		} else {
-			return &model.AnalysisResult{IsAbnormal: true, Reason: "所有图片均处理失败，无法验证"}
-		}
+	// 无需证明材料的申请类型未提供图片时，以申请级规则的结论为准
+	if len(images) == 0 && len(appData.ImageUrls) == 0 && !evidenceRequired(ctx) {
+		return &model.AnalysisResult{IsAbnormal: false, Reason: "申请信息校验通过（无需证明材料）", DecidedBy: DecidedByRules}
 	}
 
-	allImageFailures := make([]string, 0, len(imageList))
-	passedImageIndex := -1
+	allImageFailures := make([]string, 0, len(images))
+	var passedImage *model.ExtractedData
+	passedImageIndex, lowConfidence, rulesFailed := -1, false, false
 
-	for i, imageData := range imageList {
-		if imageData == nil {
-			continue
-		} // 跳过处理失败的图片占位符
+	for i, imageData := range images {
+		log.Printf("--- 正在验证图片 %d/%d ---", i+1, len(images))
+		ctx.Image, ctx.ImageIndex = imageData, imageIndexOf(imageData, i)
+		approved := imageData.Approve || imageData.IsValid
 
 		var currentImageFailures []string
-		log.Printf("--- 正在验证图片 %d/%d ---", i+1, len(imageList))
-
-		currentValidation := struct{ NameOK, DateOK, TypeOK, TimeOK bool }{
-			NameOK: true, DateOK: true, TypeOK: true, TimeOK: true,
+		currentLowConfidence := false
+		if e.useLlm() {
+			res := llmVerdictRule{}.Evaluate(ctx)
+			log.Printf("图片 %d LLM 判定: %s %s", ctx.ImageIndex, res.Outcome, res.Reason)
+			ev.record(RuleLlmVerdict, ctx.ImageIndex, res, false)
+			if res.Outcome == OutcomeFail {
+				currentImageFailures = append(currentImageFailures, res.Reason)
+			}
 		}
-
-		// 规则 4.1: 姓名验证 (逻辑不变)
-		if appData.ApplicationType == "病假" {
-			currentValidation.NameOK = false
-			if appData.Alias == "" {
-				currentImageFailures = append(currentImageFailures, "病假申请未提供申请人姓名")
-			} else if imageData.ExtractedName == "未知" {
-				currentImageFailures = append(currentImageFailures, "证明材料未体现申请人姓名")
-			} else if !strings.Contains(imageData.ExtractedName, appData.Alias) &&
-				!strings.Contains(appData.Alias, imageData.ExtractedName) {
-				currentImageFailures = append(currentImageFailures, fmt.Sprintf("证明材料姓名[%s]与申请人[%s]不符", imageData.ExtractedName, appData.Alias))
-			} else {
-				currentValidation.NameOK = true
-				log.Println("姓名验证通过")
+		if e.useRules() {
+			for _, r := range set.image {
+				if !r.AppliesTo(appData.ApplicationType) {
+					continue
+				}
+				res := r.Evaluate(ctx)
+				advisory := !e.decisive(r)
+				log.Printf("图片 %d 规则 %s: %s %s", ctx.ImageIndex, r.ID(), res.Outcome, res.Reason)
+				ev.record(r.ID(), ctx.ImageIndex, res, advisory)
+				ev.compareWithLlm(r.ID(), ctx, res, res.Outcome == OutcomeFail && !advisory && approved)
+				if advisory {
+					continue
+				}
+				if res.Outcome == OutcomeFail {
+					currentImageFailures = append(currentImageFailures, res.Reason)
+					rulesFailed = true
+				}
+				currentLowConfidence = currentLowConfidence || res.LowConfidence
 			}
-		} else {
-			log.Println("非病假申请，跳过姓名强制验证")
 		}
 
-		// 规则 4.2: 日期验证（已按需求临时跳过）
-		// 保留占位，不进行拦截和失败累积
-
-		// 规则 4.3: 类型验证（已按需求临时跳过）
-		// 保留占位，不进行拦截和失败累积
-
 		// --- 裁决当前图片 ---
-		if len(currentImageFailures) == 0 && currentValidation.NameOK && currentValidation.DateOK && currentValidation.TypeOK && currentValidation.TimeOK {
-			passedImageIndex = i + 1
+		if len(currentImageFailures) == 0 {
+			passedImage, passedImageIndex, lowConfidence = imageData, ctx.ImageIndex, currentLowConfidence
 			break
-		} else {
-			failureSummary := fmt.Sprintf("图片验证失败: (%s)", strings.Join(currentImageFailures, "；"))
-			log.Println(failureSummary)
-			allImageFailures = append(allImageFailures, failureSummary)
 		}
+		failureSummary := fmt.Sprintf("图片验证失败: (%s)", strings.Join(currentImageFailures, "；"))
+		log.Println(failureSummary)
+		allImageFailures = append(allImageFailures, failureSummary)
 	}
 
-	if passedImageIndex != -1 {
+	if passedImage != nil {
 		log.Printf("图片 %d 验证通过！", passedImageIndex)
+		ev.passedImage, ev.passedIndex = passedImage, passedImageIndex
 
 		// 构建时间警告信息（如果有OA考勤数据）
 		var timeWarning string
-		if oaAttendance != nil {
-			timeWarning = fmt.Sprintf(" (OA考勤时间: %s-%s)", oaAttendance.StandardInTime, oaAttendance.StandardOutTime)
+		if attendance != nil {
+			timeWarning = fmt.Sprintf(" (OA考勤时间: %s-%s)", attendance.StandardInTime, attendance.StandardOutTime)
 		}
 
-		finalReason := fmt.Sprintf("时间检测通过 (图片信息 验证通过)%s", timeWarning)
+		result := &model.AnalysisResult{
+			IsAbnormal:    false,
+			Reason:        fmt.Sprintf("时间检测通过 (图片信息 验证通过)%s", timeWarning),
+			LowConfidence: lowConfidence,
+		}
+		switch e.policy {
+		case PolicyLlmOnly, PolicyRulesVeto:
+			// 以 LLM 判定为准时沿用 LLM 给出的理由
+			result.DecidedBy = DecidedByLlm
+			if reason := strings.TrimSpace(passedImage.ReasonLLM); reason != "" {
+				result.Reason = reason
+			} else {
+				result.Reason = "LLM判定：通过"
+			}
+		case PolicyRulesOnly:
+			result.DecidedBy = DecidedByRules
+		default:
+			result.DecidedBy = DecidedByBoth
+		}
+		return result
+	}
+
+	finalReason := strings.Join(allImageFailures, " | ")
+	if finalReason == "" {
+		finalReason = "没有可供验证的图片"
+	}
+	log.Println(finalReason)
+	decidedBy := DecidedByLlm
+	if e.useRules() && (!e.useLlm() || rulesFailed || len(images) == 0) {
+		decidedBy = DecidedByRules
+	}
+	return &model.AnalysisResult{
+		IsAbnormal: true,
+		Reason:     finalReason,
+		DecidedBy:  decidedBy,
+	}
+}
+
+// imageIndexOf 返回图片在请求中的序号（服务层回填），未回填时按列表位置计算
+func imageIndexOf(d *model.ExtractedData, pos int) int {
+	if d.ImageIndex > 0 {
+		return d.ImageIndex
+	}
+	return pos + 1
+}
 
-		return &model.AnalysisResult{
-			IsAbnormal: false,
-			Reason:     finalReason,
+// checkOcrEvidence 使用 OCR 预识别的日期/时间直接核对补打卡申请，返回不符原因（无矛盾时返回空串）
+// - 日期：识别到日期但均不是申请日期
+// - 时间：按容差窗口核对上班卡/下班卡时间
+func checkOcrEvidence(ctx *Context) string {
+	appData, d := ctx.App, ctx.Image
+	if appData.ApplicationType != "补打卡" || d == nil {
+		return ""
+	}
+
+	if ref, ok := parseApplicationDate(appData.ApplicationDate); ok && len(d.OcrDates) > 0 {
+		matched, accepted := false, punchDates(ctx, ref)
+		for _, od := range d.OcrDates {
+			// OCR 日期可能不含年份（MM-dd），按申请日期推断年份；跨零点下班时次日也可接受
+			if day, ok := resolveDate(od, ref); ok && matchesAnyDate(day, accepted) {
+				matched = true
+				break
+			}
 		}
-	} else {
-		finalReason := strings.Join(allImageFailures, " | ")
-		log.Println(finalReason)
-		return &model.AnalysisResult{
-			IsAbnormal: true,
-			Reason:     finalReason,
+		if !matched {
+			return fmt.Sprintf("OCR识别日期[%s]与申请日期%s不符", strings.Join(d.OcrDates, ","), appData.ApplicationDate)
 		}
 	}
+
+	if len(d.OcrTimes) == 0 {
+		return ""
+	}
+	startTime, endTime := applicationPunchTimes(appData)
+	return matchPunchTimes(d.OcrTimes, startTime, endTime, ctx.Tolerance, punchClockFor(ctx), "OCR识别").reason
+}
+
+// applicationPunchTimes 返回申请的上班/下班时间（仅有单个申请时间时视为上班时间）
+func applicationPunchTimes(appData model.ApplicationData) (string, string) {
+	if appData.StartTime == "" && appData.EndTime == "" {
+		return appData.ApplicationTime, ""
+	}
+	return appData.StartTime, appData.EndTime
 }
@@ -0,0 +1,41 @@
+package rules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizePunchTime(t *testing.T) {
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+	ref := time.Date(2025, 10, 15, 0, 0, 0, 0, shanghai)
+	tests := []struct {
+		in   string
+		want string // 空串表示应返回错误
+	}{
+		{"09:00", "09:00"},
+		{"24:30", "24:30"},
+		{"次日00:30", "24:30"},
+		{"2025-10-16 00:30", "24:30"},
+		{"10-16 00:30", "24:30"},
+		{"2025-10-14 18:30", ""},
+		{"昨天 18:32", ""},
+		{"下午6点32", "18:32"},
+		{"6:32 PM", "18:32"},
+		{"00:55 UTC", "08:55"},
+		{"2025-10-14T16:50:00-08:00", "08:50"},
+		{"16:30 UTC", "24:30"},
+		{"09:00-12:00", "09:00"},
+		{"08:55-18:00", "08:55"},
+		{"09:00 打卡 UTC+9", "09:00"},
+		{"未知", ""},
+	}
+	for _, tt := range tests {
+		got, err := normalizePunchTime(tt.in, ref, shanghai)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("normalizePunchTime(%q) = %s, want error", tt.in, got)
+		case tt.want != "" && (err != nil || got != tt.want):
+			t.Errorf("normalizePunchTime(%q) = %s, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
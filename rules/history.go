@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"my-ai-app/model"
+	"my-ai-app/timezone"
+	"sort"
+	"time"
+)
+
+// HistoryRecordFor 由申请与裁决结论生成历史申请记录，申请日期无效时返回 false
+func HistoryRecordFor(app model.ApplicationData, verdict string, now time.Time) (model.HistoryRecord, bool) {
+	start, end, ok := applicationRange(app)
+	if !ok || app.UserId == "" {
+		return model.HistoryRecord{}, false
+	}
+	record := model.HistoryRecord{
+		UserId:          app.UserId,
+		ApplicationType: app.ApplicationType,
+		Date:            formatDate(start),
+		StartTime:       app.StartTime,
+		EndTime:         app.EndTime,
+		Verdict:         verdict,
+		RecordedAt:      now,
+	}
+	if !end.Equal(start) {
+		record.EndDate = formatDate(end)
+	}
+	return record, true
+}
+
+// SameApplication 两条记录是否为同一申请（同类型、同日期、同申请时间，重复提交时以最新结论为准）
+func SameApplication(a, b model.HistoryRecord) bool {
+	return a.UserId == b.UserId && a.ApplicationType == b.ApplicationType && a.Date == b.Date &&
+		a.EndDate == b.EndDate && a.StartTime == b.StartTime && a.EndTime == b.EndTime
+}
+
+// SummarizeHistory 申请人近期申请记录摘要：申请日期所在自然月与前 windowDays 天内按类型统计的次数，以及最近 recentLimit 条记录
+// 次数不含被驳回的申请与本次申请（重复提交）
+func SummarizeHistory(app model.ApplicationData, records []model.HistoryRecord, windowDays, recentLimit int) *model.HistorySummary {
+	ref, _, ok := applicationRange(app)
+	if !ok {
+		ref = time.Now().In(timezone.Default().Company())
+	}
+	current, _ := HistoryRecordFor(app, "", time.Time{})
+	monthFrom, monthTo := Frequency{}.window(ref)
+	windowFrom, windowTo := Frequency{WindowDays: windowDays}.window(ref)
+	summary := &model.HistorySummary{
+		MonthCounts:  countByType(records, current, monthFrom, monthTo),
+		WindowDays:   windowDays,
+		RecentCounts: countByType(records, current, windowFrom, windowTo),
+	}
+
+	recent := append([]model.HistoryRecord(nil), records...)
+	sort.SliceStable(recent, func(i, j int) bool { return recent[i].RecordedAt.After(recent[j].RecordedAt) })
+	if len(recent) > recentLimit {
+		recent = recent[:recentLimit]
+	}
+	summary.Recent = recent
+	return summary
+}
+
+// window 次数统计范围（含首尾）：自然月或申请日期前 WindowDays 天
+func (f Frequency) window(ref time.Time) (from, to time.Time) {
+	if f.WindowDays > 0 {
+		return ref.AddDate(0, 0, -(f.WindowDays - 1)), ref
+	}
+	from = time.Date(ref.Year(), ref.Month(), 1, 0, 0, 0, 0, ref.Location())
+	return from, from.AddDate(0, 1, -1)
+}
+
+// countByType 统计日期范围内按申请类型的次数，不含被驳回的申请与本次申请
+func countByType(records []model.HistoryRecord, current model.HistoryRecord, from, to time.Time) map[string]int {
+	counts := map[string]int{}
+	for _, r := range records {
+		if r.Verdict == model.VerdictReject || SameApplication(r, current) {
+			continue
+		}
+		d, ok := parseApplicationDate(r.Date)
+		if !ok || d.Before(from) || d.After(to) {
+			continue
+		}
+		counts[r.ApplicationType]++
+	}
+	return counts
+}
@@ -0,0 +1,85 @@
+package rules
+
+// 姓名匹配使用的字表：仅收录常见姓氏与人名用字，未收录的字不参与拼音比较
+
+// traditionalPairs 繁体 -> 简体（成对排列）
+const traditionalPairs = "" +
+	"張张陳陈劉刘黃黄楊杨趙赵吳吴孫孙馬马許许鄭郑謝谢韓韩馮冯鄧邓蕭萧葉叶蘇苏呂吕盧卢蔣蒋賈贾" +
+	"閻阎鍾钟譚谭陸陆錢钱鄒邹龍龙萬万顧顾賴赖龔龚關关溫温歐欧聶聂韋韦鄔邬華华莊庄紀纪鄺邝衛卫" +
+	"嚴严費费齊齐賀贺龐庞顏颜閔闵樂乐湯汤師师單单區区喬乔寧宁羅罗駱骆爾尔鮑鲍倫伦" +
+	"偉伟強强軍军國国劍剑鵬鹏輝辉傑杰濤涛東东興兴義义禮礼寶宝麗丽靜静紅红艷艳鳳凤嬌娇雲云飛飞" +
+	"鳴鸣陽阳豐丰榮荣長长慶庆賢贤順顺財财貴贵發发達达進进運运遠远誠诚銘铭錦锦鋒锋釗钊瑩莹蓮莲" +
+	"鶯莺鵑鹃嵐岚綺绮綠绿蘭兰穎颖潔洁譽誉勝胜樹树權权廣广濱滨瑋玮璣玑凱凯愛爱曉晓書书學学聰聪" +
+	"優优憶忆懷怀儀仪勛勋勳勋楓枫煒炜韜韬燁烨園园圓圆夢梦瑤瑶維维緯纬綱纲彥彦顯显揚扬暉晖潤润" +
+	"澤泽濟济鴻鸿淵渊漢汉滿满億亿壽寿齡龄駿骏騰腾鶴鹤時时曄晔晉晋頌颂碩硕賓宾贇赟藝艺葦苇蘊蕴" +
+	"獻献錫锡鐵铁鋼钢鈞钧銀银鏡镜鎮镇門门開开閩闽雙双靈灵韻韵風风馳驰驥骥婭娅瓊琼"
+
+// pinyinTable 拼音 -> 汉字（不带声调，ü 记为 v；多音字按姓名中的常见读音收录）
+var pinyinTable = map[string]string{
+	"ai": "艾爱蔼", "an": "安岸庵", "ang": "昂", "ao": "敖奥傲",
+	"ba": "巴八", "bai": "白柏百", "ban": "班斑", "bao": "包宝保鲍葆豹", "bei": "贝北蓓", "ben": "本贲",
+	"bi": "毕碧璧必弼", "bian": "边卞", "bin": "彬斌宾滨", "bing": "冰兵炳秉丙", "bo": "博波伯勃渤薄", "bu": "卜步",
+	"cai": "蔡才财彩采", "can": "灿璨", "cang": "苍仓", "cao": "曹草", "cen": "岑",
+	"chang": "常昌长畅嫦唱", "chao": "超朝潮巢", "chen": "陈晨辰臣琛沉宸郴谌", "cheng": "程成城承诚澄橙呈铖丞骋",
+	"chi": "池驰迟", "chong": "崇冲宠", "chu": "楚褚初储础", "chuan": "川传", "chun": "春纯淳椿", "ci": "慈词",
+	"cong": "聪丛葱琮", "cui": "崔翠萃", "cun": "存村",
+	"da": "达大", "dai": "戴代黛岱", "dan": "丹旦聃", "dang": "党", "dao": "道稻", "de": "德", "deng": "邓登灯",
+	"di": "迪狄荻笛帝娣棣", "dian": "典殿甸", "ding": "丁定鼎", "dong": "东董冬栋", "dou": "窦",
+	"du": "杜都笃督", "duan": "段端", "dun": "敦顿", "duo": "多铎",
+	"e": "娥鄂", "en": "恩", "er": "尔二",
+	"fa": "发法", "fan": "范樊凡帆繁梵", "fang": "方芳房放舫昉", "fei": "飞费菲斐妃", "fen": "芬汾奋",
+	"feng": "冯风丰凤峰锋枫封逢沣", "fu": "傅付符福富甫伏扶芙馥府复辅孚",
+	"gai": "盖", "gan": "甘干", "gang": "刚钢港纲", "gao": "高皋杲", "ge": "葛戈歌格阁", "geng": "耿庚更",
+	"gong": "龚宫公巩功恭", "gou": "苟", "gu": "顾谷古鼓固", "guan": "关管冠官观", "guang": "广光",
+	"gui": "贵桂归瑰圭", "guo": "郭国果",
+	"hai": "海", "han": "韩汉涵寒含翰晗菡瀚函邯", "hang": "航杭", "hao": "郝浩昊豪皓好灏颢",
+	"he": "何贺和河荷鹤赫", "hei": "黑", "heng": "恒衡亨", "hong": "洪红宏鸿弘虹泓", "hou": "侯厚后",
+	"hu": "胡虎湖护琥瑚扈", "hua": "华花桦骅画", "huai": "怀淮", "huan": "欢环焕桓", "huang": "黄皇煌璜凰",
+	"hui": "惠慧辉晖蕙卉会徽回汇彗", "huo": "霍火",
+	"ji": "纪吉姬季冀济计基骥玑继佶嵇戟", "jia": "贾嘉佳家甲珈", "jian": "简剑建坚健鉴舰俭",
+	"jiang": "江蒋姜疆将绛", "jiao": "焦娇皎姣蛟", "jie": "杰洁捷婕介界节",
+	"jin": "金晋锦进瑾靳津今劲谨近衿", "jing": "景静晶敬京婧菁璟竞井荆靖经镜", "jiong": "炯",
+	"jiu": "久玖九", "ju": "居菊巨举聚", "juan": "娟鹃隽", "jun": "军俊君骏钧峻郡珺",
+	"kai": "凯开恺楷", "kang": "康亢抗", "ke": "柯可克科珂", "ken": "肯", "kong": "孔空", "kou": "寇",
+	"kuang": "邝匡旷", "kun": "坤昆琨",
+	"lai": "赖来莱", "lan": "兰蓝岚澜斓", "lang": "郎朗琅", "lao": "劳", "le": "乐勒", "lei": "雷磊蕾垒",
+	"leng": "冷", "li": "李黎丽莉力立利礼理历俐璃栗厉励骊", "lian": "连莲廉炼涟恋", "liang": "梁良亮靓凉",
+	"liao": "廖辽了", "lie": "烈", "lin": "林琳霖麟淋临凛蔺", "ling": "凌玲灵铃龄菱翎岭令伶羚",
+	"liu": "刘柳留流琉", "long": "龙隆珑泷", "lou": "楼娄", "lu": "卢陆鲁路露鹿璐芦禄录潞麓",
+	"lv": "吕律绿旅", "luan": "栾", "lun": "伦轮", "luo": "罗骆洛落珞",
+	"ma": "马玛", "mai": "麦迈", "man": "满曼蔓", "mang": "芒", "mao": "毛茂茅贸", "mei": "梅美媚玫眉",
+	"men": "门", "meng": "孟蒙梦萌猛", "mi": "米宓弥蜜", "miao": "苗妙淼缪", "min": "敏民闵珉旻岷闽",
+	"ming": "明鸣铭名茗", "mo": "莫墨默茉沫", "mu": "穆木牧慕沐睦",
+	"na": "娜纳那", "nan": "南楠男", "neng": "能", "ni": "倪妮霓尼", "nie": "聂", "nian": "年念", "ning": "宁凝柠",
+	"niu": "牛妞", "nong": "农", "ou": "欧区鸥",
+	"pan": "潘攀盼磐", "pang": "庞", "pei": "裴佩培沛", "peng": "彭鹏朋蓬", "pi": "皮", "piao": "朴",
+	"ping": "平萍屏坪", "pu": "蒲浦普璞溥",
+	"qi": "齐祁戚琪琦奇启起棋旗淇麒祺骐绮其岐七綦", "qian": "钱倩谦乾千茜芊黔潜", "qiang": "强蔷",
+	"qiao": "乔巧桥俏谯", "qin": "秦勤琴钦沁芹覃", "qing": "庆青清晴卿情擎倾", "qiong": "琼穹",
+	"qiu": "邱秋丘仇球求", "qu": "曲屈瞿渠璩", "quan": "全权泉荃诠", "que": "阙雀鹊", "qun": "群",
+	"ran": "冉然燃", "rao": "饶", "ren": "任仁人韧", "ri": "日", "rong": "荣容蓉融戎榕熔溶",
+	"ru": "汝如茹儒", "rui": "瑞睿锐蕊芮", "run": "润", "ruo": "若",
+	"sa": "萨", "san": "三", "sen": "森", "sha": "沙莎纱", "shan": "山单珊善杉姗闪", "shang": "尚商上",
+	"shao": "邵少韶绍", "she": "佘舍", "shen": "沈申深神慎莘燊绅", "sheng": "盛胜生圣晟升声笙",
+	"shi": "石史施时诗世师实士拾仕", "shou": "寿守首", "shu": "舒书淑树蜀殊曙述叔", "shuai": "帅",
+	"shuang": "双霜爽", "shui": "水", "shun": "顺舜", "shuo": "硕烁", "si": "司思斯丝四嗣",
+	"song": "宋松嵩颂淞", "su": "苏素肃粟宿", "sui": "隋岁穗遂", "sun": "孙笋", "suo": "索",
+	"tai": "台泰太", "tan": "谭谈檀坦", "tang": "唐汤棠堂", "tao": "陶涛桃韬滔", "teng": "滕腾藤",
+	"tian": "田天甜恬", "tie": "铁", "ting": "婷亭庭廷霆挺", "tong": "童佟彤通桐同瞳", "tu": "涂屠图", "tuan": "团",
+	"wan": "万宛婉晚菀琬", "wang": "王汪旺望", "wei": "魏韦卫伟薇维威蔚巍玮炜纬苇唯惟微",
+	"wen": "温文闻雯稳汶", "weng": "翁", "wo": "沃", "wu": "吴武伍吾五乌邬午悟舞务",
+	"xi": "席西希喜熙曦夕溪锡奚羲习", "xia": "夏霞侠", "xian": "冼先贤仙显娴献鲜弦",
+	"xiang": "向项香祥翔湘相想襄", "xiao": "萧肖晓小笑潇孝筱啸", "xie": "谢解燮协",
+	"xin": "辛新欣心信鑫馨昕忻芯", "xing": "邢星兴幸杏行", "xiong": "熊雄", "xiu": "修秀",
+	"xu": "徐许旭续绪胥栩煦", "xuan": "宣轩玄萱璇暄炫", "xue": "薛雪学", "xun": "荀勋寻迅珣讯",
+	"ya": "雅亚娅涯", "yan": "严颜燕阎言彦妍艳焱岩延晏闫炎雁琰", "yang": "杨阳羊洋扬仰央",
+	"yao": "姚尧瑶耀遥", "ye": "叶野业烨晔", "yi": "易伊依仪怡义艺一毅亿逸宜奕懿忆益翼",
+	"yin": "尹殷银印音寅茵", "ying": "应英莹颖鹰迎莺樱盈影瑛映滢", "yong": "永勇咏雍泳",
+	"you": "尤游友优佑有悠幽", "yu": "于余俞虞郁玉宇雨鱼禹予羽语钰誉育愉瑜昱煜渝毓裕",
+	"yuan": "袁元原圆远源园媛苑渊沅", "yue": "岳月悦越跃", "yun": "云运韵芸允昀筠蕴赟耘",
+	"zai": "载再", "zan": "赞", "zang": "臧", "zao": "早", "ze": "泽则", "zeng": "曾增", "zha": "查扎",
+	"zhai": "翟", "zhan": "詹展战湛", "zhang": "张章彰璋樟", "zhao": "赵照招昭钊", "zhe": "哲浙喆",
+	"zhen": "甄真珍振贞镇臻震祯桢", "zheng": "郑正征政峥铮", "zhi": "智志芝之知治致植枝直稚",
+	"zhong": "钟仲中忠", "zhou": "周舟州洲宙", "zhu": "朱祝竹珠诸铸柱筑", "zhuang": "庄壮",
+	"zhuo": "卓琢灼", "zi": "紫子梓姿资自孜", "zong": "宗", "zou": "邹", "zu": "祖", "zuo": "左作佐",
+}
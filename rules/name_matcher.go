@@ -0,0 +1,264 @@
+package rules
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameMatchThreshold 姓名相似度达到该值视为同一人
+const NameMatchThreshold = 0.8
+
+// 姓名匹配方式
+const (
+	NameMatchExact      = "exact"      // 规范化后完全一致
+	NameMatchMasked     = "masked"     // 脱敏姓名（如 张*明）在可见位置一致
+	NameMatchContains   = "contains"   // 一方包含另一方（如带称谓、括注）
+	NameMatchPinyin     = "pinyin"     // 拼音一致（拼音姓名或同音字）
+	NameMatchSimilarity = "similarity" // 编辑距离相似度
+)
+
+// NameMatch 姓名匹配结果
+type NameMatch struct {
+	Score  float64 // 相似度 0~1
+	Method string  // 匹配方式
+}
+
+// 脱敏占位符统一为 maskRune
+const maskRune = '*'
+
+// maxNameRunes 参与匹配的姓名长度上限，超出部分截断（姓名来自请求与 OCR，需限制匹配开销）
+const maxNameRunes = 32
+
+var (
+	simplifiedOf = map[rune]rune{}
+	pinyinOf     = map[rune]string{}
+)
+
+func init() {
+	pairs := []rune(traditionalPairs)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		simplifiedOf[pairs[i]] = pairs[i+1]
+	}
+	for syllable, chars := range pinyinTable {
+		for _, c := range chars {
+			pinyinOf[c] = syllable
+		}
+	}
+}
+
+// MatchName 比较申请人姓名与证明材料上的姓名
+// 依次尝试：完全一致、脱敏匹配、包含、拼音、编辑距离相似度，返回得分最高的结果
+func MatchName(alias, extracted string) NameMatch {
+	a, e := normalizeName(alias), normalizeName(extracted)
+	a, e = a[:min(len(a), maxNameRunes)], e[:min(len(e), maxNameRunes)]
+	if len(a) == 0 || len(e) == 0 {
+		return NameMatch{Method: NameMatchSimilarity}
+	}
+	if string(a) == string(e) {
+		return NameMatch{Score: 1, Method: NameMatchExact}
+	}
+
+	best := NameMatch{Score: nameSimilarity(a, e), Method: NameMatchSimilarity}
+	consider := func(score float64, method string) {
+		if score > best.Score {
+			best = NameMatch{Score: score, Method: method}
+		}
+	}
+	if hasMask(e) && !hasMask(a) {
+		consider(maskedScore(e, a), NameMatchMasked)
+	} else if hasMask(a) && !hasMask(e) {
+		consider(maskedScore(a, e), NameMatchMasked)
+	}
+	if (len(a) >= 2 && strings.Contains(string(e), string(a))) || (len(e) >= 2 && strings.Contains(string(a), string(e))) {
+		consider(0.9, NameMatchContains)
+	}
+	consider(pinyinScore(a, e), NameMatchPinyin)
+	return best
+}
+
+// normalizeName 规范化姓名：去空白与间隔号、全角转半角、字母小写、繁体转简体、脱敏符号统一为 *
+func normalizeName(s string) []rune {
+	var out []rune
+	for _, r := range strings.TrimSpace(s) {
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0 // 全角 ASCII
+		}
+		switch {
+		case unicode.IsSpace(r) || strings.ContainsRune("·•・.-_,，、'", r):
+			continue
+		case strings.ContainsRune("*＊×○〇□?？", r):
+			r = maskRune
+		case r < unicode.MaxASCII:
+			r = unicode.ToLower(r)
+		default:
+			if simplified, ok := simplifiedOf[r]; ok {
+				r = simplified
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func hasMask(name []rune) bool {
+	for _, r := range name {
+		if r == maskRune {
+			return true
+		}
+	}
+	return false
+}
+
+// maskedScore 脱敏姓名与完整姓名的匹配得分
+// 每个 * 对应一个字且可见字全部一致得 0.9；连续的 * 对应任意多个字时得 0.8；至少要有一个可见字
+func maskedScore(masked, full []rune) float64 {
+	visible := 0
+	for _, r := range masked {
+		if r != maskRune {
+			visible++
+		}
+	}
+	if visible == 0 {
+		return 0
+	}
+	if len(masked) == len(full) {
+		ok := true
+		for i, r := range masked {
+			if r != maskRune && r != full[i] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return 0.9
+		}
+	}
+	if globMatch(masked, full) {
+		return 0.8
+	}
+	return 0
+}
+
+// globMatch 连续的 * 匹配一个或多个字
+// 贪心匹配：只回溯到最近一段 * 并让它多匹配一个字，时间复杂度 O(len(pattern)*len(s))
+func globMatch(pattern, s []rune) bool {
+	p, i := 0, 0
+	starP, starI := -1, 0 // 最近一段 * 之后的模式位置，及该段已匹配到的字符串位置
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == maskRune:
+			for p < len(pattern) && pattern[p] == maskRune {
+				p++
+			}
+			i++ // 一段 * 至少匹配一个字
+			starP, starI = p, i
+		case p < len(pattern) && pattern[p] == s[i]:
+			p++
+			i++
+		case starP >= 0:
+			starI++
+			p, i = starP, starI
+		default:
+			return false
+		}
+	}
+	return p == len(pattern)
+}
+
+// pinyinScore 拼音比较：一方为拼音时按姓在前/名在前比较得 0.85；双方均为汉字且读音一致（同音字）得 0.8
+func pinyinScore(a, e []rune) float64 {
+	switch {
+	case isLatin(a) && !isLatin(e):
+		return romanizedScore(e, a)
+	case isLatin(e) && !isLatin(a):
+		return romanizedScore(a, e)
+	case isLatin(a) || isLatin(e):
+		return 0
+	}
+	pa, ok := toPinyin(a)
+	if !ok {
+		return 0
+	}
+	pe, ok := toPinyin(e)
+	if !ok || len(pa) != len(pe) {
+		return 0
+	}
+	for i := range pa {
+		if pa[i] != pe[i] {
+			return 0
+		}
+	}
+	return 0.8
+}
+
+// romanizedScore 汉字姓名与拼音姓名比较，支持 "zhangsan"、"sanzhang"（名在前）及复姓
+func romanizedScore(han, latin []rune) float64 {
+	syllables, ok := toPinyin(han)
+	if !ok {
+		return 0
+	}
+	target := normalizeRomanized(string(latin))
+	for surname := 1; surname <= 2 && surname < len(syllables); surname++ {
+		given := strings.Join(syllables[surname:], "")
+		family := strings.Join(syllables[:surname], "")
+		if target == normalizeRomanized(family+given) || target == normalizeRomanized(given+family) {
+			return 0.85
+		}
+	}
+	if len(syllables) == 1 && target == normalizeRomanized(syllables[0]) {
+		return 0.85
+	}
+	return 0
+}
+
+// normalizeRomanized ü 的各种拼写（v、yu、ü）统一为 u
+func normalizeRomanized(s string) string {
+	return strings.NewReplacer("lyu", "lu", "nyu", "nu", "lv", "lu", "nv", "nu", "ü", "u").Replace(s)
+}
+
+// toPinyin 逐字转换为拼音，存在未收录的字时返回 false
+func toPinyin(name []rune) ([]string, bool) {
+	syllables := make([]string, 0, len(name))
+	for _, r := range name {
+		p, ok := pinyinOf[r]
+		if !ok {
+			return nil, false
+		}
+		syllables = append(syllables, p)
+	}
+	return syllables, true
+}
+
+func isLatin(name []rune) bool {
+	for _, r := range name {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// nameSimilarity 基于编辑距离的相似度
+func nameSimilarity(a, b []rune) float64 {
+	maxLen := len(a)
+	if len(b) > maxLen {
+		maxLen = len(b)
+	}
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] && a[i-1] != maskRune {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return 1 - float64(prev[len(b)])/float64(maxLen)
+}
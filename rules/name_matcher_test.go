@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchName(t *testing.T) {
+	tests := []struct {
+		alias, extracted string
+		method           string
+		match            bool // 得分是否达到 NameMatchThreshold
+	}{
+		{"张三", "张三", NameMatchExact, true},
+		{"张三", " 张 三 ", NameMatchExact, true},
+		{"张伟", "張偉", NameMatchExact, true},
+		{"欧阳娜娜", "歐陽娜娜", NameMatchExact, true},
+		{"Zhang San", "ｚｈａｎｇ　ｓａｎ", NameMatchExact, true},
+		{"张伟明", "张*明", NameMatchMasked, true},
+		{"张伟明", "张＊明", NameMatchMasked, true},
+		{"张伟明", "张**", NameMatchMasked, true},
+		{"欧阳娜娜", "欧*", NameMatchMasked, true},
+		{"张伟明", "李*明", NameMatchSimilarity, false},
+		{"张伟明", "***", NameMatchSimilarity, false},
+		{"张伟", "患者：张伟", NameMatchContains, true},
+		{"张伟", "zhangwei", NameMatchPinyin, true},
+		{"张伟", "Wei Zhang", NameMatchPinyin, true},
+		{"欧阳娜娜", "nana ouyang", NameMatchPinyin, true},
+		{"吕丽", "Lv Li", NameMatchPinyin, true},
+		{"张伟", "章伟", NameMatchPinyin, true},
+		{"张伟", "zhangsan", NameMatchSimilarity, false},
+		{"张伟明", "张伟民", NameMatchSimilarity, false},
+		{"王小明", "李四", NameMatchSimilarity, false},
+		{"", "张三", NameMatchSimilarity, false},
+	}
+	for _, tt := range tests {
+		got := MatchName(tt.alias, tt.extracted)
+		if got.Method != tt.method || (got.Score >= NameMatchThreshold) != tt.match {
+			t.Errorf("MatchName(%q, %q) = %.2f/%s, want %s match=%v", tt.alias, tt.extracted, got.Score, got.Method, tt.method, tt.match)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"张*明", "张伟明", true},
+		{"张*明", "张明", false}, // * 至少匹配一个字
+		{"张**", "张伟明", true},
+		{"张*", "张", false},
+		{"*明", "张伟明", true},
+		{"*伟*", "张伟明", true},
+		{"*伟*", "伟明", false},
+		{"欧*娜", "欧阳娜娜", true},
+		{"欧*娜*", "欧阳娜娜", true},
+		{"欧*娜*", "欧阳娜", false},
+		{"张*明", "张伟民", false},
+		{"*", "", false},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		if got := globMatch([]rune(tt.pattern), []rune(tt.s)); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestMatchNameMaskBacktracking(t *testing.T) {
+	// 交替的脱敏符与可见字在不匹配时曾导致指数级回溯
+	alias := strings.Repeat("*a", 14) + "*b"
+	extracted := strings.Repeat("a", 40)
+	start := time.Now()
+	if got := MatchName(alias, extracted); got.Score >= NameMatchThreshold {
+		t.Errorf("MatchName = %.2f/%s, want 不匹配", got.Score, got.Method)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("MatchName 耗时 %v", elapsed)
+	}
+}
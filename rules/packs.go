@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"fmt"
+	"my-ai-app/model"
+	"strings"
+)
+
+// Pack 申请类型的规则包参数：必填字段、可接受的证明材料类型与证明材料日期范围
+// 规则包的规则列表见 builtinPackRules，参数由 required_fields / require_images / evidence_type / evidence_date / frequency 规则读取
+type Pack struct {
+	RequiredFields   []string    `yaml:"required_fields,omitempty" json:"required_fields,omitempty"`     // 必填字段，见 requiredFieldLabels
+	EvidenceRequired bool        `yaml:"evidence_required,omitempty" json:"evidence_required,omitempty"` // 是否必须提供证明材料
+	EvidenceTypes    []string    `yaml:"evidence_types,omitempty" json:"evidence_types,omitempty"`       // 可接受的证明材料类型关键词，为空表示不限
+	DateWindow       *DateWindow `yaml:"date_window,omitempty" json:"date_window,omitempty"`             // 证明材料日期相对申请日期的允许范围，为空表示不核对
+	Frequency        *Frequency  `yaml:"frequency,omitempty" json:"frequency,omitempty"`                 // 同类型申请的次数上限，为空表示不限
+}
+
+// DateWindow 证明材料日期允许的范围（单位：天），多日申请时相对开始/结束日期计算
+type DateWindow struct {
+	DaysBefore int `yaml:"days_before" json:"days_before"` // 最多早于申请（开始）日期 N 天
+	DaysAfter  int `yaml:"days_after" json:"days_after"`   // 最多晚于申请（结束）日期 N 天
+}
+
+// Frequency 同类型申请的次数上限：window_days 为 0 时按申请日期所在自然月统计，否则按申请日期前 N 天（含当天）滚动统计
+type Frequency struct {
+	MaxCount   int `yaml:"max_count" json:"max_count"`                         // 统计范围内最多申请次数（含本次）
+	WindowDays int `yaml:"window_days,omitempty" json:"window_days,omitempty"` // 滚动统计天数，0 表示自然月
+}
+
+// requiredFieldLabels 可配置的必填字段
+var requiredFieldLabels = map[string]string{
+	"alias":            "申请人姓名",
+	"reason":           "申请理由",
+	"application_date": "申请日期",
+	"start_time":       "开始时间",
+	"end_time":         "结束时间",
+	"time":             "申请时间",
+}
+
+// fieldValue 返回申请中必填字段的值
+func fieldValue(ctx *Context, field string) string {
+	app := ctx.App
+	switch field {
+	case "alias":
+		return app.Alias
+	case "reason":
+		return app.Reason
+	case "application_date":
+		return app.ApplicationDate + app.StartDate
+	case "start_time":
+		return app.StartTime
+	case "end_time":
+		return app.EndTime
+	case "time":
+		return app.StartTime + app.EndTime + app.ApplicationTime
+	}
+	return ""
+}
+
+// BuiltinPacks 内置规则包参数（按申请类型）
+func BuiltinPacks() map[string]Pack {
+	travel := Pack{
+		RequiredFields:   []string{"application_date", "reason"},
+		EvidenceRequired: true,
+		EvidenceTypes:    []string{"出差", "外出", "审批", "机票", "登机牌", "火车票", "车票", "行程单", "酒店", "打车", "聊天记录"},
+		DateWindow:       &DateWindow{DaysBefore: 3, DaysAfter: 3},
+	}
+	return map[string]Pack{
+		"补打卡": {Frequency: &Frequency{MaxCount: 3}},
+		"病假":  {Frequency: &Frequency{MaxCount: 3, WindowDays: 30}},
+		"事假":  {RequiredFields: []string{"application_date", "reason"}},
+		"年假":  {RequiredFields: []string{"application_date"}},
+		"调休":  {RequiredFields: []string{"application_date"}},
+		"加班": {
+			RequiredFields: []string{"application_date", "start_time", "end_time", "reason"},
+			EvidenceTypes:  []string{"加班", "审批", "工作记录", "聊天记录", "系统", "打卡记录", "截图"},
+			DateWindow:     &DateWindow{DaysAfter: 1}, // 加班可能跨零点
+		},
+		"外出": travel,
+		"出差": travel,
+		"婚假": {
+			RequiredFields:   []string{"application_date"},
+			EvidenceRequired: true,
+			EvidenceTypes:    []string{"结婚证", "结婚登记"},
+			DateWindow:       &DateWindow{DaysBefore: 365},
+		},
+		"产假": {
+			RequiredFields:   []string{"application_date"},
+			EvidenceRequired: true,
+			EvidenceTypes:    []string{"出生证明", "出生医学证明", "诊断证明", "病历", "住院", "出院", "孕检", "生育服务证", "准生证"},
+			DateWindow:       &DateWindow{DaysBefore: 180, DaysAfter: 30},
+		},
+	}
+}
+
+// builtinPackRules 内置规则包的规则集
+func builtinPackRules() map[string][]RuleSpec {
+	noEvidence := []RuleSpec{{ID: RuleRequiredFields}, {ID: RuleDateRange}, {ID: RuleFrequency}}
+	withEvidence := func(nameOnEvidence bool) []RuleSpec {
+		specs := []RuleSpec{
+			{ID: RuleRequiredFields},
+			{ID: RuleDateRange},
+			{ID: RuleFrequency},
+			{ID: RuleRequireImages},
+			{ID: RuleTamper},
+		}
+		if nameOnEvidence {
+			specs = append(specs, RuleSpec{ID: RuleNameMatch, AppliesTo: []string{"*"}})
+		}
+		return append(specs, RuleSpec{ID: RuleEvidenceType}, RuleSpec{ID: RuleEvidenceDate}, RuleSpec{ID: RuleRangeCoverage})
+	}
+	return map[string][]RuleSpec{
+		"事假": noEvidence,
+		"年假": noEvidence,
+		"调休": noEvidence,
+		"加班": {{ID: RuleRequiredFields}, {ID: RuleDateRange}, {ID: RuleFrequency}, {ID: RuleTamper}, {ID: RuleEvidenceType}, {ID: RuleEvidenceDate}},
+		"外出": withEvidence(false),
+		"出差": withEvidence(false),
+		"婚假": withEvidence(true),
+		"产假": withEvidence(true),
+	}
+}
+
+// evidenceRequired 申请是否必须提供证明材料（病假、补打卡及规则包要求证明材料的类型）
+func evidenceRequired(ctx *Context) bool {
+	appType := ctx.App.ApplicationType
+	return appType == "病假" || appType == "补打卡" || ctx.Pack.EvidenceRequired
+}
+
+// evidenceText 用于匹配证明材料类型的图片文本（证明类型、内容与关键词）
+func evidenceText(d *model.ExtractedData) string {
+	return strings.Join([]string{d.ProofType, d.Content, d.Keywords}, " ")
+}
+
+// describe 统计范围的说明文字
+func (f Frequency) describe() string {
+	if f.WindowDays > 0 {
+		return fmt.Sprintf("近 %d 天", f.WindowDays)
+	}
+	return "本月"
+}
+
+// describeWindow 日期范围的说明文字
+func describeWindow(w DateWindow) string {
+	switch {
+	case w.DaysBefore > 0 && w.DaysAfter > 0:
+		return fmt.Sprintf("申请日期前 %d 天至后 %d 天内", w.DaysBefore, w.DaysAfter)
+	case w.DaysBefore > 0:
+		return fmt.Sprintf("申请日期前 %d 天内", w.DaysBefore)
+	case w.DaysAfter > 0:
+		return fmt.Sprintf("申请日期当天至后 %d 天内", w.DaysAfter)
+	}
+	return "申请日期当天"
+}
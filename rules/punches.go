@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"my-ai-app/schedule"
+	"sort"
+	"strings"
+)
+
+// SuspiciousPunchMinutes 已有打卡晚于补卡上班时间 / 早于补卡下班时间不超过该分钟数时，疑似将迟到/早退改为补卡
+const SuspiciousPunchMinutes = 5
+
+// 打卡槽位
+const (
+	slotStart = "上班卡"
+	slotEnd   = "下班卡"
+)
+
+// punchList 当天的打卡记录：换算到申请日期与班次的时间轴（分钟数，>= 1440 为次日），去重并升序
+type punchList []int
+
+// punchListFor 当天的打卡记录（申请附带的 attendance_info 与 OA 考勤），无法解析的时间忽略
+func punchListFor(ctx *Context, clock punchClock) punchList {
+	seen := map[int]bool{}
+	var list punchList
+	for _, t := range attendancePunches(ctx) {
+		if m, ok := clock.minutes(t); ok && !seen[m] {
+			seen[m] = true
+			list = append(list, m)
+		}
+	}
+	sort.Ints(list)
+	return list
+}
+
+// String 打卡记录的展示文本，如 "08:50,次日00:30"
+func (p punchList) String() string {
+	parts := make([]string, len(p))
+	for i, m := range p {
+		parts[i] = schedule.FormatMinutes(m)
+	}
+	return strings.Join(parts, ",")
+}
+
+// coveringStart 覆盖上班卡的打卡：不晚于申请时间的打卡；申请时间不早于班次上班时间时，按班次未迟到的打卡亦可
+// 提前上班（加班）的申请需有不晚于申请时间的打卡
+func (p punchList) coveringStart(target int, shift schedule.Shift) (int, bool) {
+	for _, m := range p {
+		if m <= target || (target >= shift.StartMinutes() && m <= shift.LatestStartMinutes()) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// coveringEnd 覆盖下班卡的打卡：不早于申请时间的打卡（加班到次日的下班卡不因当天已有正常下班打卡而被覆盖）
+func (p punchList) coveringEnd(target int) (int, bool) {
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i] >= target {
+			return p[i], true
+		}
+	}
+	return 0, false
+}
+
+// missingSlots 按首末次打卡判断当天缺失的卡：首次打卡晚于班次最晚上班时间视为缺上班卡，
+// 仅一次打卡或末次打卡早于最早下班时间视为缺下班卡（仅一次且晚于最早下班时间的打卡视为下班卡）
+func (p punchList) missingSlots(shift schedule.Shift) []string {
+	if len(p) == 0 {
+		return []string{slotStart, slotEnd}
+	}
+	first, last := p[0], p[len(p)-1]
+	hasStart := first <= shift.LatestStartMinutes()
+	hasEnd := last >= shift.EarliestEndMinutes(first)
+	if len(p) == 1 && hasStart {
+		hasEnd = false
+	}
+	if len(p) == 1 && hasEnd {
+		hasStart = false
+	}
+	var missing []string
+	if !hasStart {
+		missing = append(missing, slotStart)
+	}
+	if !hasEnd {
+		missing = append(missing, slotEnd)
+	}
+	return missing
+}
+
+// after 晚于 target 且不超过 window 分钟的最早打卡
+func (p punchList) after(target, window int) (int, bool) {
+	for _, m := range p {
+		if m > target && m <= target+window {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// before 早于 target 且不超过 window 分钟的最晚打卡
+func (p punchList) before(target, window int) (int, bool) {
+	for i := len(p) - 1; i >= 0; i-- {
+		if m := p[i]; m < target && m >= target-window {
+			return m, true
+		}
+	}
+	return 0, false
+}
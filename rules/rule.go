@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"fmt"
+	"my-ai-app/model"
+	"my-ai-app/schedule"
+	"time"
+)
+
+// Outcome 单条规则的评估结果
+type Outcome string
+
+const (
+	OutcomePass Outcome = "pass" // 通过
+	OutcomeFail Outcome = "fail" // 不通过
+	OutcomeSkip Outcome = "skip" // 不适用/缺少数据，跳过
+)
+
+// Scope 规则作用范围
+type Scope string
+
+const (
+	ScopeApplication Scope = "application" // 申请级：每个申请评估一次，不通过即整体驳回
+	ScopeImage       Scope = "image"       // 图片级：逐张图片评估，任意一张图片全部通过即整体通过
+)
+
+// Context 规则评估上下文
+type Context struct {
+	App        model.ApplicationData
+	Attendance *model.AttendanceData  // OA 提供的当天考勤（未接入 OA 时为 nil）
+	History    []model.HistoryRecord  // 申请人的历史申请（未启用申请记录时为 nil）
+	Images     []*model.ExtractedData // 全部成功分析的图片
+	Image      *model.ExtractedData   // 图片级规则当前评估的图片
+	ImageIndex int                    // 当前图片序号（从1开始）
+	Tolerance  Tolerance              // 按申请类型与部门选定的时间容差
+	Pack       Pack                   // 申请类型对应的规则包参数（未配置时为零值）
+	Shift      schedule.Shift         // 申请人当天的班次（OA 下发了标准上下班时间时以 OA 为准，未配置排班时为标准班）
+	Location   *time.Location         // 申请人所在时区（办公地时区，未配置时为公司时区）
+}
+
+// Result 规则评估结果
+type Result struct {
+	Outcome Outcome
+	Reason  string
+	Inputs  map[string]string // 规则使用的输入（写入规则追踪，便于排查）
+	// LowConfidence 仅在容差窗口内通过
+	LowConfidence bool
+}
+
+// With 附加规则输入，参数为成对的 key、value
+func (r Result) With(kv ...string) Result {
+	if r.Inputs == nil {
+		r.Inputs = make(map[string]string, len(kv)/2)
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		r.Inputs[kv[i]] = kv[i+1]
+	}
+	return r
+}
+
+// Pass 通过
+func Pass() Result { return Result{Outcome: OutcomePass} }
+
+// Fail 不通过，附带原因
+func Fail(format string, args ...interface{}) Result {
+	return Result{Outcome: OutcomeFail, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Skip 跳过，附带原因
+func Skip(reason string) Result { return Result{Outcome: OutcomeSkip, Reason: reason} }
+
+// Rule 规则接口
+// 新规则实现该接口并在 init 中调用 Register，即可在规则配置文件中按 ID 引用
+type Rule interface {
+	ID() string                    // 规则ID（配置文件中引用）
+	Scope() Scope                  // 作用范围
+	AppliesTo(appType string) bool // 默认适用的申请类型（可被配置中的 applies_to 覆盖）
+	Evaluate(ctx *Context) Result  // 评估
+}
+
+// registry 已注册的规则
+var registry = map[string]Rule{}
+
+// Register 注册规则，ID 重复时 panic
+func Register(r Rule) {
+	if _, exists := registry[r.ID()]; exists {
+		panic(fmt.Sprintf("规则 %s 重复注册", r.ID()))
+	}
+	registry[r.ID()] = r
+}
+
+// Lookup 按 ID 查找已注册的规则
+func Lookup(id string) (Rule, bool) {
+	r, ok := registry[id]
+	return r, ok
+}
+
+// Vetoer 可选接口：声明规则为硬性规则，rules_veto 策略下其不通过可推翻 LLM 的通过结论
+// 未实现该接口的规则在 rules_veto 下仅记录；可被配置中的 veto 覆盖
+type Vetoer interface {
+	Veto() bool
+}
+
+// matchesType 申请类型是否在列表中（"*" 匹配全部）
+func matchesType(types []string, appType string) bool {
+	for _, t := range types {
+		if t == "*" || t == appType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package rules
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleSpec 规则配置项
+type RuleSpec struct {
+	ID        string   `yaml:"id" json:"id"`                                     // 规则ID
+	Enabled   *bool    `yaml:"enabled,omitempty" json:"enabled,omitempty"`       // 是否启用（默认启用）
+	AppliesTo []string `yaml:"applies_to,omitempty" json:"applies_to,omitempty"` // 覆盖规则默认适用的申请类型（"*" 表示全部）
+	Veto      *bool    `yaml:"veto,omitempty" json:"veto,omitempty"`             // rules_veto 策略下能否推翻 LLM 的通过结论（默认按规则是否为硬性规则）
+}
+
+// RuleConfig 规则配置文件（YAML 或 JSON）
+// default 为未单独配置的申请类型使用的规则集；types 按申请类型配置规则集，列表顺序即评估顺序
+// packs 为按申请类型配置的规则包参数；未在配置中出现的申请类型沿用内置规则包（规则集与参数）
+// verdict_policy 为 LLM 与规则集的裁决策略，见 Policy* 常量
+type RuleConfig struct {
+	VerdictPolicy string                `yaml:"verdict_policy,omitempty" json:"verdict_policy,omitempty"`
+	Default       []RuleSpec            `yaml:"default" json:"default"`
+	Types         map[string][]RuleSpec `yaml:"types,omitempty" json:"types,omitempty"`
+	Tolerances    []ToleranceSpec       `yaml:"tolerances,omitempty" json:"tolerances,omitempty"` // 补打卡时间容差窗口（按申请类型/部门）
+	Packs         map[string]Pack       `yaml:"packs,omitempty" json:"packs,omitempty"`
+}
+
+// 裁决策略：LLM 层（图片的 approve/is_valid 判定）与规则层（规则集）如何共同决定结果
+// 多图规则不变：任意一张图片通过即整体通过
+const (
+	PolicyLlmOnly      = "llm_only"       // 仅采用 LLM 判定，不评估规则集
+	PolicyRulesOnly    = "rules_only"     // 仅由规则集裁决，LLM 判定不参与
+	PolicyLlmThenRules = "llm_then_rules" // LLM 判定通过且规则集全部通过才通过
+	PolicyRulesVeto    = "rules_veto"     // 默认：以 LLM 判定为准，硬性规则（veto）不通过时推翻；其余规则仅记录
+)
+
+// 裁决层（AnalysisResult.DecidedBy）
+const (
+	DecidedByLlm   = "llm"
+	DecidedByRules = "rules"
+	DecidedByBoth  = "llm+rules"
+)
+
+// DefaultRuleConfig 内置规则配置（类型校验默认关闭），包含内置规则包
+func DefaultRuleConfig() *RuleConfig {
+	disabled := false
+	return &RuleConfig{
+		Types: builtinPackRules(),
+		Packs: BuiltinPacks(),
+		Default: []RuleSpec{
+			{ID: RuleRequireApplicationTime},
+			{ID: RuleExistingPunch},
+			{ID: RuleShiftTime},
+			{ID: RulePunchPattern},
+			{ID: RuleFrequency},
+			{ID: RuleDateRange},
+			{ID: RuleRequireImages},
+			{ID: RuleTamper},
+			{ID: RuleNameMatch},
+			{ID: RuleRangeCoverage},
+			{ID: RuleOcrEvidence},
+			{ID: RuleDateMatch},
+			{ID: RuleTimeMatch},
+			{ID: RuleTypeMatch, Enabled: &disabled},
+		},
+	}
+}
+
+// LoadRuleConfig 从文件加载规则配置（JSON 是 YAML 的子集，统一按 YAML 解析）
+func LoadRuleConfig(path string) (*RuleConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取规则配置失败: %w", err)
+	}
+	var cfg RuleConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析规则配置失败: %w", err)
+	}
+	builtin := DefaultRuleConfig()
+	if len(cfg.Default) == 0 {
+		cfg.Default = builtin.Default
+	}
+	if cfg.Types == nil {
+		cfg.Types = map[string][]RuleSpec{}
+	}
+	for appType, specs := range builtin.Types {
+		if _, ok := cfg.Types[appType]; !ok {
+			cfg.Types[appType] = specs
+		}
+	}
+	if cfg.Packs == nil {
+		cfg.Packs = map[string]Pack{}
+	}
+	for appType, pack := range builtin.Packs {
+		if _, ok := cfg.Packs[appType]; !ok {
+			cfg.Packs[appType] = pack
+		}
+	}
+	return &cfg, nil
+}
+
+// compiledRule 按配置项解析后的规则
+type compiledRule struct {
+	Rule
+	types []string // 配置中覆盖的适用类型，为空时使用规则默认值
+	veto  bool
+}
+
+func (r compiledRule) AppliesTo(appType string) bool {
+	if len(r.types) > 0 {
+		return matchesType(r.types, appType)
+	}
+	return r.Rule.AppliesTo(appType)
+}
+
+// ruleSet 编译后的规则集
+type ruleSet struct {
+	application []compiledRule
+	image       []compiledRule
+}
+
+// compileRuleSet 按配置顺序解析规则，未知规则ID返回错误
+func compileRuleSet(specs []RuleSpec) (ruleSet, error) {
+	var set ruleSet
+	for _, spec := range specs {
+		if spec.ID == RuleLlmVerdict {
+			return ruleSet{}, fmt.Errorf("%s 由 verdict_policy 控制，无需在规则集中配置", RuleLlmVerdict)
+		}
+		r, ok := Lookup(spec.ID)
+		if !ok {
+			return ruleSet{}, fmt.Errorf("未知的规则ID: %s", spec.ID)
+		}
+		if spec.Enabled != nil && !*spec.Enabled {
+			continue
+		}
+		cr := compiledRule{Rule: r, types: spec.AppliesTo}
+		if v, ok := r.(Vetoer); ok {
+			cr.veto = v.Veto()
+		}
+		if spec.Veto != nil {
+			cr.veto = *spec.Veto
+		}
+		switch r.Scope() {
+		case ScopeApplication:
+			set.application = append(set.application, cr)
+		case ScopeImage:
+			set.image = append(set.image, cr)
+		}
+	}
+	return set, nil
+}
+
+// NewEngineFromFile 从配置文件创建规则引擎；path 为空或加载失败时使用内置规则配置
+// policy 非空时覆盖配置中的 verdict_policy
+func NewEngineFromFile(path, policy string) *Engine {
+	if path == "" && policy == "" {
+		return DefaultEngine()
+	}
+	cfg := DefaultRuleConfig()
+	if path != "" {
+		loaded, err := LoadRuleConfig(path)
+		if err == nil {
+			_, err = NewEngine(loaded)
+		}
+		if err != nil {
+			log.Printf("规则配置 %s 无效，使用内置规则: %v", path, err)
+		} else {
+			log.Printf("已从 %s 加载规则配置（类型规则集: %d）", path, len(loaded.Types))
+			cfg = loaded
+		}
+	}
+	if policy != "" {
+		cfg.VerdictPolicy = policy
+	}
+	engine, err := NewEngine(cfg)
+	if err != nil {
+		log.Printf("裁决策略 %s 无效，使用内置规则: %v", policy, err)
+		return DefaultEngine()
+	}
+	log.Printf("裁决策略: %s", engine.policy)
+	return engine
+}
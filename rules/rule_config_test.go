@@ -0,0 +1,32 @@
+package rules
+
+import "testing"
+
+// 示例配置中设置了 frequency 参数的申请类型，其规则集须包含 frequency 规则，否则上限不会生效
+func TestExampleConfigRunsConfiguredFrequency(t *testing.T) {
+	cfg, err := LoadRuleConfig("rules.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEngine(cfg); err != nil {
+		t.Fatalf("示例配置无法编译: %v", err)
+	}
+	for appType, pack := range cfg.Packs {
+		if pack.Frequency == nil {
+			continue
+		}
+		specs, ok := cfg.Types[appType]
+		if !ok {
+			specs = cfg.Default
+		}
+		found := false
+		for _, spec := range specs {
+			if spec.ID == RuleFrequency && (spec.Enabled == nil || *spec.Enabled) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s 配置了 frequency 参数，但规则集中未启用 frequency 规则", appType)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package rules
+
+import (
+	"fmt"
+	"my-ai-app/model"
+	"my-ai-app/schedule"
+	"strings"
+	"time"
+)
+
+// Tolerance 补打卡时间核对的容差窗口（单位：分钟）
+// 严格规则：上班卡证据时间 <= 上班时间，下班卡证据时间 >= 下班时间
+type Tolerance struct {
+	GraceMinutes       int `yaml:"grace_minutes" json:"grace_minutes"`               // 上班卡晚于 / 下班卡早于申请时间 N 分钟内仍可接受，但标记为低置信度
+	MaxDistanceMinutes int `yaml:"max_distance_minutes" json:"max_distance_minutes"` // 证据时间与申请时间相差超过 N 分钟视为无关证据（0 表示不限制）
+}
+
+// ToleranceSpec 容差配置项：按申请类型与部门匹配，列表中第一条匹配的配置生效
+type ToleranceSpec struct {
+	Types       []string `yaml:"types,omitempty" json:"types,omitempty"`             // 适用的申请类型，省略表示全部
+	Departments []string `yaml:"departments,omitempty" json:"departments,omitempty"` // 适用的部门，省略表示全部
+	Tolerance   `yaml:",inline"`
+}
+
+// matches 配置项是否适用于该申请类型与部门
+func (t ToleranceSpec) matches(appType, department string) bool {
+	return (len(t.Types) == 0 || matchesType(t.Types, appType)) &&
+		(len(t.Departments) == 0 || matchesType(t.Departments, department))
+}
+
+// toleranceFor 返回申请类型与部门对应的容差，未配置时为严格匹配
+func toleranceFor(specs []ToleranceSpec, appType, department string) Tolerance {
+	for _, spec := range specs {
+		if spec.matches(appType, department) {
+			return spec.Tolerance
+		}
+	}
+	return Tolerance{}
+}
+
+// punchMatch 证据时间与申请时间的核对结果
+type punchMatch struct {
+	ok            bool   // 是否满足
+	lowConfidence bool   // 仅在容差窗口内满足
+	reason        string // 不满足原因
+}
+
+// matchPunchTimes 按容差核对证据时间（HH:mm 列表）是否支持上班/下班时间（为空表示未申请）
+// 时间按 clock 换算后比较，跨零点的下班卡与次日凌晨的证据时间可直接比较
+// source 为证据来源描述，用于拼接不满足原因，如 "证明材料"、"OCR识别"
+func matchPunchTimes(times []string, startTime, endTime string, tol Tolerance, clock punchClock, source string) punchMatch {
+	result := punchMatch{ok: true}
+	check := func(target string, isStart bool) {
+		targetMin, ok := clock.minutes(target)
+		if !ok || !result.ok {
+			return
+		}
+		strict, grace, tooFar := false, false, false
+		for _, t := range times {
+			m, ok := clock.minutes(t)
+			if !ok {
+				continue
+			}
+			// diff > 0 表示证据时间在严格规则允许的一侧（上班卡早于、下班卡晚于申请时间）
+			diff := targetMin - m
+			if !isStart {
+				diff = -diff
+			}
+			switch {
+			case diff >= 0 && (tol.MaxDistanceMinutes <= 0 || diff <= tol.MaxDistanceMinutes):
+				strict = true
+			case diff >= 0:
+				tooFar = true
+			case -diff <= tol.GraceMinutes:
+				grace = true
+			}
+		}
+		label, relation := "上班时间", "均晚于"
+		if !isStart {
+			label, relation = "下班时间", "均早于"
+		}
+		switch {
+		case strict:
+		case grace:
+			result.lowConfidence = true
+		case tooFar:
+			result.ok = false
+			result.reason = fmt.Sprintf("%s时间[%s]距%s%s超过 %d 分钟", source, strings.Join(times, ","), label, target, tol.MaxDistanceMinutes)
+		default:
+			result.ok = false
+			result.reason = fmt.Sprintf("%s时间[%s]%s%s%s", source, strings.Join(times, ","), relation, label, target)
+			if tol.GraceMinutes > 0 {
+				result.reason += fmt.Sprintf("（容差 %d 分钟）", tol.GraceMinutes)
+			}
+		}
+	}
+	check(startTime, true)
+	check(endTime, false)
+	return result
+}
+
+// clockMinutes 将时间解析为分钟数，次日时间（如 "24:30"）>= 1440
+func clockMinutes(s string) (int, bool) {
+	nt, err := normalizeTimeFormat(s)
+	if err != nil {
+		return 0, false
+	}
+	var h, m int
+	if _, err := fmt.Sscanf(nt, "%d:%d", &h, &m); err != nil || h > 47 || m > 59 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+// punchClock 将打卡时间换算到申请日期与班次的时间轴上：带日期或标明次日的时间按申请日期换算，
+// 其余时间按班次对齐（明显早于上班时间的视为次日，如加班到 00:30 下班）
+type punchClock struct {
+	ref   time.Time // 申请日期，零值表示未知
+	shift schedule.Shift
+	loc   *time.Location // 员工所在时区，证据时间标注了时区时换算到该时区
+}
+
+// punchClockFor 当前申请的打卡时间轴
+func punchClockFor(ctx *Context) punchClock {
+	ref, _ := parseApplicationDate(ctx.App.ApplicationDate)
+	return punchClock{ref: ref, shift: ctx.Shift, loc: ctx.Location}
+}
+
+// PunchMinutes 申请中的打卡时间（"09:00"、"下午6点"、"次日00:30"、"2025-10-16 00:30" 等）按申请日期、班次与员工时区
+// 换算到班次时间轴的分钟数（>= 1440 为次日），与规则引擎的换算一致；无法识别或不在申请日期当天/次日时返回 false
+func PunchMinutes(app model.ApplicationData, shift schedule.Shift, loc *time.Location, s string) (int, bool) {
+	ref, _ := parseApplicationDate(app.ApplicationDate)
+	return punchClock{ref: ref, shift: shift, loc: loc}.minutes(s)
+}
+
+// minutes 打卡时间在时间轴上的分钟数（>= 1440 为次日）
+func (c punchClock) minutes(s string) (int, bool) {
+	nt, err := normalizePunchTime(s, c.ref, c.loc)
+	if err != nil {
+		return 0, false
+	}
+	m, ok := clockMinutes(nt)
+	if !ok {
+		return 0, false
+	}
+	return c.shift.Align(m), true
+}
+
+// normalize 打卡时间换算后的 HH:mm（次日时间小时数加 24）
+func (c punchClock) normalize(s string) (string, bool) {
+	m, ok := c.minutes(s)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%02d:%02d", m/60, m%60), true
+}
@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"my-ai-app/schedule"
+)
+
+func TestMatchPunchTimes(t *testing.T) {
+	day := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
+	standard := punchClock{ref: day, shift: schedule.StandardShift(), loc: time.UTC}
+	night := punchClock{ref: day, shift: schedule.Shift{Name: "night", Start: "22:00", End: "06:00"}, loc: time.UTC}
+	tests := []struct {
+		name       string
+		times      []string
+		start, end string
+		tol        Tolerance
+		clock      punchClock
+		ok, low    bool
+		reason     string // 不满足原因需包含的片段
+	}{
+		{"上班卡早于申请时间", []string{"08:50"}, "09:00", "", Tolerance{}, standard, true, false, ""},
+		{"上班卡晚到且无容差", []string{"09:05"}, "09:00", "", Tolerance{}, standard, false, false, "均晚于上班时间09:00"},
+		{"上班卡在容差内", []string{"09:05"}, "09:00", "", Tolerance{GraceMinutes: 10}, standard, true, true, ""},
+		{"上班卡超出容差", []string{"09:15"}, "09:00", "", Tolerance{GraceMinutes: 10}, standard, false, false, "（容差 10 分钟）"},
+		{"下班卡早退在容差内", []string{"17:55"}, "", "18:00", Tolerance{GraceMinutes: 10}, standard, true, true, ""},
+		{"证据距申请时间过远", []string{"06:00"}, "09:00", "", Tolerance{MaxDistanceMinutes: 120}, standard, false, false, "超过 120 分钟"},
+		{"任一时间在距离窗口内即可", []string{"06:00", "08:30"}, "09:00", "", Tolerance{MaxDistanceMinutes: 120}, standard, true, false, ""},
+		{"严格匹配优先于容差", []string{"09:05", "08:58"}, "09:00", "", Tolerance{GraceMinutes: 10}, standard, true, false, ""},
+		{"加班到次日凌晨", []string{"00:30"}, "", "次日00:30", Tolerance{}, standard, true, false, ""},
+		{"24 小时制次日写法", []string{"24:40"}, "", "次日00:30", Tolerance{}, standard, true, false, ""},
+		{"带日期的次日证据", []string{"2025-10-16 00:35"}, "", "2025-10-16 00:30", Tolerance{}, standard, true, false, ""},
+		{"次日凌晨早于申请下班时间", []string{"00:20"}, "", "次日00:30", Tolerance{}, standard, false, false, "均早于下班时间次日00:30"},
+		{"夜班次日下班", []string{"06:10"}, "", "06:00", Tolerance{}, night, true, false, ""},
+		{"夜班上下班均满足", []string{"21:50", "06:05"}, "22:00", "06:00", Tolerance{}, night, true, false, ""},
+		{"夜班次日早退在容差内", []string{"21:50", "05:55"}, "22:00", "06:00", Tolerance{GraceMinutes: 10}, night, true, true, ""},
+		{"夜班跨零点不视为距离过远", []string{"05:30"}, "", "06:00", Tolerance{GraceMinutes: 60, MaxDistanceMinutes: 120}, night, true, true, ""},
+		{"无法识别的证据时间", []string{"快一点"}, "09:00", "", Tolerance{}, standard, false, false, "均晚于上班时间09:00"},
+		{"未申请时间", []string{"10:00"}, "", "", Tolerance{}, standard, true, false, ""},
+	}
+	for _, tt := range tests {
+		got := matchPunchTimes(tt.times, tt.start, tt.end, tt.tol, tt.clock, "证明材料")
+		if got.ok != tt.ok || got.lowConfidence != tt.low {
+			t.Errorf("%s: ok=%v low=%v (%s), want ok=%v low=%v", tt.name, got.ok, got.lowConfidence, got.reason, tt.ok, tt.low)
+			continue
+		}
+		if tt.reason != "" && !strings.Contains(got.reason, tt.reason) {
+			t.Errorf("%s: reason = %q, want 包含 %q", tt.name, got.reason, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"fmt"
+	"log"
+	"my-ai-app/model"
+)
+
+// TamperReviewScore 篡改风险分达到该值（未达 TamperRejectScore，或仅有压缩痕迹）的图片据以通过时转人工复核
+const TamperReviewScore = 0.4
+
+// FlagForReview 将结果标记为需人工复核并记录原因
+func FlagForReview(result *model.AnalysisResult, reason string) {
+	log.Printf("转人工复核: %s", reason)
+	result.Verdict = model.VerdictNeedsReview
+	result.ReviewReasons = append(result.ReviewReasons, reason)
+}
+
+// assess 给出三态结论
+// 通过时，以下情况转人工复核：仅在容差窗口内通过、据以通过的图片有未推翻 LLM 的规则不符或仅记录的规则不通过、存在编辑痕迹
+// 驳回时，由 LLM 驳回而确定性规则核对一致的转人工复核
+func (ev *evaluation) assess(result *model.AnalysisResult) {
+	if result.IsAbnormal {
+		result.Verdict = model.VerdictReject
+		if result.DecidedBy != DecidedByLlm {
+			return
+		}
+		for _, d := range result.LlmDisagreements {
+			if d.RuleMatch && !d.LlmMatch {
+				FlagForReview(result, fmt.Sprintf("图片 %d LLM 驳回，但规则核对%s一致", d.ImageIndex, fieldLabel(d.Field)))
+			}
+		}
+		return
+	}
+
+	result.Verdict = model.VerdictApprove
+	if result.LowConfidence {
+		FlagForReview(result, "通过依据仅落在时间容差窗口内")
+	}
+	for _, t := range result.RuleTrace {
+		if t.ImageIndex == 0 && t.Advisory && t.Outcome == string(OutcomeFail) {
+			FlagForReview(result, fmt.Sprintf("规则 %s 未通过: %s", t.RuleID, t.Message))
+		}
+	}
+	if ev.passedImage == nil {
+		return
+	}
+	flagged := map[string]bool{}
+	for _, d := range result.LlmDisagreements {
+		if d.ImageIndex == ev.passedIndex && !d.RuleMatch && !d.Overridden {
+			FlagForReview(result, fmt.Sprintf("图片 %d 规则与 LLM 结论不一致: %s", d.ImageIndex, d.Message))
+			flagged[d.Field] = true
+		}
+	}
+	for _, t := range result.RuleTrace {
+		if flagged[llmMatchFlags[t.RuleID].field] {
+			continue // 已作为分歧记录
+		}
+		if t.ImageIndex == ev.passedIndex && t.Advisory && t.Outcome == string(OutcomeFail) {
+			FlagForReview(result, fmt.Sprintf("图片 %d 规则 %s 未通过: %s", t.ImageIndex, t.RuleID, t.Message))
+		}
+	}
+	if score := ev.passedImage.TamperRiskScore; score >= TamperReviewScore {
+		FlagForReview(result, fmt.Sprintf("图片 %d 存在编辑痕迹（风险分 %.2f）", ev.passedIndex, score))
+	}
+}
+
+// fieldLabel 分歧字段的中文名称
+func fieldLabel(field string) string {
+	if field == "date" {
+		return "日期"
+	}
+	return "时间"
+}
@@ -0,0 +1,316 @@
+// Package schedule 班次与排班：定义各班次的上下班时间、弹性窗口与跨零点夜班，并按员工/部门确定当天班次
+// 排班配置通过 SHIFT_CONFIG_PATH 指定（示例见 shifts.example.yaml），未配置时所有人按 09:00~18:00 标准班
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StandardShiftName = "standard" // 内置标准班
+	OAShiftName       = "oa"       // 按 OA 下发的当天标准上下班时间确定的班次
+	dayMinutes        = 24 * 60
+	// 早于上班时间超过该分钟数的时刻视为次日（如 22:00 上班时 06:10 为次日 06:10，09:00 上班加班到 00:30 下班）
+	nextDayMarginMinutes = 6 * 60
+)
+
+// Shift 班次
+type Shift struct {
+	Name        string `yaml:"-" json:"name"`
+	Start       string `yaml:"start" json:"start"`                                   // 上班时间 HH:mm
+	End         string `yaml:"end" json:"end"`                                       // 下班时间 HH:mm，不晚于上班时间表示跨零点（次日下班）
+	FlexMinutes int    `yaml:"flex_minutes,omitempty" json:"flex_minutes,omitempty"` // 弹性窗口：上班最多可晚到 N 分钟，下班时间随实际上班时间顺延
+}
+
+// StandardShift 内置标准班 09:00~18:00
+func StandardShift() Shift {
+	return Shift{Name: StandardShiftName, Start: "09:00", End: "18:00"}
+}
+
+// StartMinutes 上班时间（当天分钟数）
+func (s Shift) StartMinutes() int {
+	m, _ := parseShiftClock(s.Start)
+	return m
+}
+
+// EndMinutes 下班时间的分钟数，跨零点班次为次日（>= 1440）
+func (s Shift) EndMinutes() int {
+	m, _ := parseShiftClock(s.End)
+	if s.CrossesMidnight() {
+		m += dayMinutes
+	}
+	return m
+}
+
+// WithStandardTimes 以 OA 下发的当天标准上下班时间（HH:mm）覆盖班次时间，弹性窗口沿用排班配置；
+// 任一时间为空或无法解析时返回原班次
+func (s Shift) WithStandardTimes(start, end string) Shift {
+	start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+	if _, ok := parseShiftClock(start); !ok {
+		return s
+	}
+	if _, ok := parseShiftClock(end); !ok {
+		return s
+	}
+	if start == s.Start && end == s.End {
+		return s
+	}
+	return Shift{Name: OAShiftName, Start: start, End: end, FlexMinutes: s.FlexMinutes}
+}
+
+// CrossesMidnight 是否跨零点（下班时间不晚于上班时间）
+func (s Shift) CrossesMidnight() bool {
+	start, ok1 := parseShiftClock(s.Start)
+	end, ok2 := parseShiftClock(s.End)
+	return ok1 && ok2 && end <= start
+}
+
+// LatestStartMinutes 不算迟到的最晚上班时间（含弹性窗口）
+func (s Shift) LatestStartMinutes() int {
+	return s.StartMinutes() + s.FlexMinutes
+}
+
+// EarliestEndMinutes 不算早退的最早下班时间：弹性班次按实际上班时间顺延（最多顺延 flex_minutes），clockIn < 0 表示未知
+func (s Shift) EarliestEndMinutes(clockIn int) int {
+	end := s.EndMinutes()
+	if s.FlexMinutes > 0 && clockIn >= 0 {
+		end += min(max(s.Align(clockIn)-s.StartMinutes(), 0), s.FlexMinutes)
+	}
+	return end
+}
+
+// Align 将当天时刻（分钟数）对齐到班次时间轴：明显早于上班时间的时刻视为次日（+1440），已是次日（>= 1440）的不变
+func (s Shift) Align(m int) int {
+	if m < dayMinutes && m < s.StartMinutes()-nextDayMarginMinutes {
+		return m + dayMinutes
+	}
+	return m
+}
+
+// OnTimeIn 上班打卡时间（分钟数，>= 1440 为次日；当天时刻按 Align 对齐）是否未迟到
+func (s Shift) OnTimeIn(m int) bool {
+	return s.Align(m) <= s.LatestStartMinutes()
+}
+
+// OnTimeOut 下班打卡时间（分钟数）是否未早退，clockIn 为当天上班打卡时间的分钟数（< 0 表示未知）
+func (s Shift) OnTimeOut(m, clockIn int) bool {
+	return s.Align(m) >= s.EarliestEndMinutes(clockIn)
+}
+
+// Describe 班次说明，如 "night 22:00~次日06:00" / "flex 09:00~18:00（弹性 60 分钟）"
+func (s Shift) Describe() string {
+	end := s.End
+	if s.CrossesMidnight() {
+		end = "次日" + end
+	}
+	desc := fmt.Sprintf("%s %s~%s", s.Name, s.Start, end)
+	if s.FlexMinutes > 0 {
+		desc += fmt.Sprintf("（弹性 %d 分钟）", s.FlexMinutes)
+	}
+	return desc
+}
+
+// FormatMinutes 将班次时间轴上的分钟数格式化为 HH:mm（次日时加 "次日" 前缀）
+func FormatMinutes(m int) string {
+	prefix := ""
+	if m >= dayMinutes {
+		prefix, m = "次日", m-dayMinutes
+	}
+	return fmt.Sprintf("%s%02d:%02d", prefix, m/60, m%60)
+}
+
+// parseClock 解析 HH:mm / H:mm；次日时间写作 "24:30" 或 "次日00:30"，返回值 >= 1440
+func parseClock(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	nextDay := 0
+	for _, prefix := range []string{"次日", "翌日"} {
+		if rest, ok := strings.CutPrefix(s, prefix); ok {
+			s, nextDay = strings.TrimSpace(rest), dayMinutes
+			break
+		}
+	}
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || h < 0 || h > 47 || m < 0 || m > 59 {
+		return 0, false
+	}
+	if total := h*60 + m + nextDay; total < 2*dayMinutes {
+		return total, true
+	}
+	return 0, false
+}
+
+// parseShiftClock 解析班次定义中的时间，须为当天的 HH:mm
+func parseShiftClock(s string) (int, bool) {
+	m, ok := parseClock(s)
+	return m, ok && m < dayMinutes
+}
+
+// Source 排班来源：返回员工在某天的班次名称，未排班时返回 false
+// 内置实现为配置文件中的 assignments，也可对接 OA 排班接口
+type Source interface {
+	ShiftName(userID, department string, date time.Time) (string, bool)
+}
+
+// Assignment 排班规则：按员工 ID 或部门指定班次，可限定生效日期
+type Assignment struct {
+	UserIDs     []string `yaml:"user_ids,omitempty"`
+	Departments []string `yaml:"departments,omitempty"`
+	Shift       string   `yaml:"shift"`
+	From        string   `yaml:"from,omitempty"` // 生效开始日期 yyyy-MM-dd，省略表示不限
+	To          string   `yaml:"to,omitempty"`   // 生效结束日期 yyyy-MM-dd，省略表示不限
+}
+
+// matches 排班规则是否适用于该员工与日期
+func (a Assignment) matches(userID, department string, date time.Time) bool {
+	if len(a.UserIDs) > 0 && !contains(a.UserIDs, userID) {
+		return false
+	}
+	if len(a.Departments) > 0 && !contains(a.Departments, department) {
+		return false
+	}
+	if date.IsZero() {
+		return a.From == "" && a.To == ""
+	}
+	day := date.Format("2006-01-02")
+	return (a.From == "" || day >= a.From) && (a.To == "" || day <= a.To)
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// assignmentSource 按配置文件中的排班规则确定班次：列表顺序即优先级，应先写员工级再写部门级
+type assignmentSource []Assignment
+
+func (s assignmentSource) ShiftName(userID, department string, date time.Time) (string, bool) {
+	for _, a := range s {
+		if a.matches(userID, department, date) {
+			return a.Shift, true
+		}
+	}
+	return "", false
+}
+
+// Config 排班配置文件
+type Config struct {
+	DefaultShift string           `yaml:"default_shift"`
+	Shifts       map[string]Shift `yaml:"shifts"`
+	Assignments  []Assignment     `yaml:"assignments"`
+}
+
+// Schedule 班次表与排班来源
+type Schedule struct {
+	shifts       map[string]Shift
+	defaultShift string
+	source       Source
+}
+
+// New 创建排班；未包含 standard 班次时自动加入内置标准班，defaultShift 为空时使用 standard
+func New(shifts map[string]Shift, defaultShift string, source Source) (*Schedule, error) {
+	all := map[string]Shift{StandardShiftName: StandardShift()}
+	for name, shift := range shifts {
+		shift.Name = name
+		if _, ok := parseShiftClock(shift.Start); !ok {
+			return nil, fmt.Errorf("班次 %s 的上班时间[%s]无效", name, shift.Start)
+		}
+		if _, ok := parseShiftClock(shift.End); !ok {
+			return nil, fmt.Errorf("班次 %s 的下班时间[%s]无效", name, shift.End)
+		}
+		if shift.FlexMinutes < 0 {
+			return nil, fmt.Errorf("班次 %s 的 flex_minutes 不能为负数", name)
+		}
+		all[name] = shift
+	}
+	if defaultShift == "" {
+		defaultShift = StandardShiftName
+	}
+	if _, ok := all[defaultShift]; !ok {
+		return nil, fmt.Errorf("默认班次 %s 未定义", defaultShift)
+	}
+	if as, ok := source.(assignmentSource); ok {
+		for _, a := range as {
+			if _, ok := all[a.Shift]; !ok {
+				return nil, fmt.Errorf("排班引用了未定义的班次 %s", a.Shift)
+			}
+		}
+	}
+	return &Schedule{shifts: all, defaultShift: defaultShift, source: source}, nil
+}
+
+// LoadFile 从 YAML/JSON 文件加载排班配置
+func LoadFile(path string) (*Schedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取排班配置失败: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析排班配置失败: %w", err)
+	}
+	return New(cfg.Shifts, cfg.DefaultShift, assignmentSource(cfg.Assignments))
+}
+
+// WithSource 替换排班来源（如对接 OA 排班接口），班次表不变
+func (s *Schedule) WithSource(source Source) *Schedule {
+	return &Schedule{shifts: s.shifts, defaultShift: s.defaultShift, source: source}
+}
+
+// Shift 按名称查询班次
+func (s *Schedule) Shift(name string) (Shift, bool) {
+	shift, ok := s.shifts[name]
+	return shift, ok
+}
+
+// ShiftFor 员工某天的班次：排班来源未指定或指定了未定义的班次时使用默认班次
+func (s *Schedule) ShiftFor(userID, department string, date time.Time) Shift {
+	if s.source != nil {
+		if name, ok := s.source.ShiftName(userID, department, date); ok {
+			if shift, ok := s.shifts[name]; ok {
+				return shift
+			}
+		}
+	}
+	return s.shifts[s.defaultShift]
+}
+
+// ShiftForDate 同 ShiftFor，日期为 yyyy-MM-dd（无法解析时仅匹配未限定生效日期的排班规则）
+func (s *Schedule) ShiftForDate(userID, department, date string) Shift {
+	d, err := time.Parse("2006-01-02", strings.TrimSpace(date))
+	if err != nil {
+		d = time.Time{}
+	}
+	return s.ShiftFor(userID, department, d)
+}
+
+var (
+	defaultMu          sync.RWMutex
+	defaultSchedule, _ = New(nil, "", nil)
+)
+
+// Default 全局排班，默认所有人为标准班
+func Default() *Schedule {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultSchedule
+}
+
+// SetDefault 替换全局排班（启动时按 SHIFT_CONFIG_PATH 加载后调用）
+func SetDefault(s *Schedule) {
+	if s == nil {
+		return
+	}
+	defaultMu.Lock()
+	defaultSchedule = s
+	defaultMu.Unlock()
+}
@@ -0,0 +1,170 @@
+package schedule
+
+import "testing"
+
+var (
+	night = Shift{Name: "night", Start: "22:00", End: "06:00"}
+	flex  = Shift{Name: "flex", Start: "09:00", End: "18:00", FlexMinutes: 60}
+)
+
+func TestAlign(t *testing.T) {
+	tests := []struct {
+		shift Shift
+		in    string
+		want  string
+	}{
+		{StandardShift(), "08:55", "08:55"},
+		{StandardShift(), "18:30", "18:30"},
+		{StandardShift(), "03:00", "03:00"},   // 距上班不足 6 小时，仍为当天
+		{StandardShift(), "02:30", "次日02:30"}, // 加班到凌晨
+		{StandardShift(), "00:30", "次日00:30"},
+		{StandardShift(), "次日00:30", "次日00:30"},
+		{StandardShift(), "24:30", "次日00:30"},
+		{night, "21:50", "21:50"},
+		{night, "16:30", "16:30"},
+		{night, "06:10", "次日06:10"},
+		{night, "00:00", "次日00:00"},
+		{night, "15:59", "次日15:59"},
+	}
+	for _, tt := range tests {
+		m, ok := parseClock(tt.in)
+		if !ok {
+			t.Fatalf("parseClock(%q) 失败", tt.in)
+		}
+		if got := FormatMinutes(tt.shift.Align(m)); got != tt.want {
+			t.Errorf("%s.Align(%s) = %s, want %s", tt.shift.Name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestOnTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		shift   Shift
+		in, out string // 空串表示未打卡
+		onIn    bool
+		onOut   bool
+	}{
+		{"标准班准时", StandardShift(), "08:58", "18:02", true, true},
+		{"标准班迟到早退", StandardShift(), "09:01", "17:59", false, false},
+		{"标准班加班到次日", StandardShift(), "09:00", "00:30", true, true},
+		{"夜班准时", night, "21:55", "06:05", true, true},
+		{"夜班次日早退", night, "22:00", "05:50", true, false},
+		{"夜班当天下班视为早退", night, "22:00", "23:30", true, false},
+		{"夜班次日凌晨上班视为迟到", night, "00:10", "06:30", false, true},
+		{"弹性班窗口内上班", flex, "09:40", "18:40", true, true},
+		{"弹性班下班顺延", flex, "09:40", "18:30", true, false},
+		{"弹性班超出窗口", flex, "10:05", "19:00", false, true},
+		{"弹性班未知上班时间", flex, "", "18:00", false, true},
+	}
+	for _, tt := range tests {
+		clockIn := -1
+		if tt.in != "" {
+			clockIn, _ = parseClock(tt.in)
+			if got := tt.shift.OnTimeIn(clockIn); got != tt.onIn {
+				t.Errorf("%s: OnTimeIn(%s) = %v, want %v", tt.name, tt.in, got, tt.onIn)
+			}
+		}
+		out, _ := parseClock(tt.out)
+		if got := tt.shift.OnTimeOut(out, clockIn); got != tt.onOut {
+			t.Errorf("%s: OnTimeOut(%s) = %v, want %v", tt.name, tt.out, got, tt.onOut)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"09:00", 540, true},
+		{"9:05", 545, true},
+		{"24:30", 1470, true},
+		{"次日00:30", 1470, true},
+		{"翌日 06:00", 1800, true},
+		{"次日24:00", 0, false},
+		{"48:00", 0, false},
+		{"09:60", 0, false},
+		{"九点", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseClock(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseClock(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestShiftDescribe(t *testing.T) {
+	if got := night.Describe(); got != "night 22:00~次日06:00" {
+		t.Errorf("Describe = %q", got)
+	}
+	if got := flex.Describe(); got != "flex 09:00~18:00（弹性 60 分钟）" {
+		t.Errorf("Describe = %q", got)
+	}
+	if !night.CrossesMidnight() || StandardShift().CrossesMidnight() {
+		t.Error("CrossesMidnight 判断错误")
+	}
+	if got := night.EndMinutes(); got != 1800 {
+		t.Errorf("night.EndMinutes = %d, want 1800", got)
+	}
+}
+
+func TestWithStandardTimes(t *testing.T) {
+	tests := []struct {
+		start, end string
+		want       string
+	}{
+		{"08:30", "17:30", "oa 08:30~17:30（弹性 60 分钟）"},
+		{"09:00", "18:00", "flex 09:00~18:00（弹性 60 分钟）"},
+		{"22:00", "06:00", "oa 22:00~次日06:00（弹性 60 分钟）"},
+		{"", "17:30", "flex 09:00~18:00（弹性 60 分钟）"},
+		{"08:30", "次日01:00", "flex 09:00~18:00（弹性 60 分钟）"},
+	}
+	for _, tt := range tests {
+		if got := flex.WithStandardTimes(tt.start, tt.end).Describe(); got != tt.want {
+			t.Errorf("WithStandardTimes(%q, %q) = %q, want %q", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestLoadFileExample(t *testing.T) {
+	s, err := LoadFile("shifts.example.yaml")
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	tests := []struct {
+		userID, department, date string
+		want                     string
+	}{
+		{"10086", "研发部", "2025-10-15", "night"},
+		{"10086", "研发部", "2025-11-01", "flex"},
+		{"10010", "研发部", "", "flex"},
+		{"10010", "财务部", "2025-10-15", StandardShiftName},
+	}
+	for _, tt := range tests {
+		if got := s.ShiftForDate(tt.userID, tt.department, tt.date).Name; got != tt.want {
+			t.Errorf("ShiftForDate(%s, %s, %s) = %s, want %s", tt.userID, tt.department, tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestNewRejectsInvalidShift(t *testing.T) {
+	tests := []map[string]Shift{
+		{"bad": {Start: "25:00", End: "06:00"}},
+		{"bad": {Start: "22:00", End: "次日06:00"}},
+		{"bad": {Start: "09:00", End: "18:00", FlexMinutes: -1}},
+	}
+	for _, shifts := range tests {
+		if _, err := New(shifts, "", nil); err == nil {
+			t.Errorf("New(%v) 应返回错误", shifts)
+		}
+	}
+	if _, err := New(nil, "night", nil); err == nil {
+		t.Error("未定义的默认班次应返回错误")
+	}
+	if _, err := New(nil, "", assignmentSource{{Shift: "night", From: "2025-10-01"}}); err == nil {
+		t.Error("排班引用未定义的班次应返回错误")
+	}
+}
@@ -60,7 +60,7 @@ func shiftDate(t time.Time, delta int) string {
 }
 
 func shiftTime(h, m, deltaMin int) string {
-	t := time.Date(2000, 1, 1, h, m, 0, 0, time.Local)
+	t := time.Date(2000, 1, 1, h, m, 0, 0, time.UTC) // 仅做时钟加减，使用 UTC 避免本地夏令时影响
 	nt := t.Add(time.Duration(deltaMin) * time.Minute)
 	return nt.Format("15:04")
 }
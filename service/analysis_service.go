@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"mime/multipart"
@@ -15,15 +16,54 @@ import (
 
 // AnalysisService 同时持有所有客户端
 type AnalysisService struct {
-	qwenClient    *client.QwenClient    // 通义千问客户端
-	volcanoClient *client.VolcanoClient // 火山引擎客户端
+	qwenClient     *client.QwenClient    // 通义千问客户端
+	volcanoClient  *client.VolcanoClient // 火山引擎客户端
+	tamperDetector *TamperDetector       // 图片篡改检测器（nil 表示关闭）
+	verdictCache   *VerdictCache         // LLM 判定缓存（nil 表示关闭）
+	pdfMaxPages    int                   // PDF 证据最多处理的页数
+	maxImagePixels int                   // 单张图片像素上限
+	maxImageBytes  int64                 // 单张图片字节上限（URL 图片下载时同样适用）
+	enhanceProfile map[string][]string   // 按申请类型配置的图片增强方案
+	ocrEngine      client.OcrEngine      // LLM 之前的 OCR 预识别（nil 表示关闭）
+	ruleEngine     *rules.Engine         // 规则引擎（按申请类型加载规则集）
+	timeValidator  *TimeValidator        // 时间校验（工作日、迟到早退、风险级别）
+	oaClient       client.OaBackend      // OA 系统（nil 表示未接入）
+	history        *HistoryStore         // 员工历史申请记录（nil 表示关闭）
 }
 
 // NewAnalysisService 注入所有客户端
 func NewAnalysisService(cfg *config.Config) *AnalysisService {
-	return &AnalysisService{
-		qwenClient:    client.NewQwenClient(cfg.QwenApiURL, cfg.QwenApiKey),
-		volcanoClient: client.NewVolcanoClient(cfg.VolcanoApiURL, cfg.VolcanoApiKey),
+	s := &AnalysisService{
+		qwenClient:     client.NewQwenClient(cfg.QwenApiURL, cfg.QwenApiKey),
+		volcanoClient:  client.NewVolcanoClient(cfg.VolcanoApiURL, cfg.VolcanoApiKey),
+		pdfMaxPages:    cfg.PdfMaxPages,
+		maxImagePixels: cfg.MaxImagePixels,
+		maxImageBytes:  cfg.MaxUploadFileBytes,
+		enhanceProfile: client.ParseEnhanceProfiles(cfg.ImageEnhanceProfiles),
+		ocrEngine:      client.NewOcrEngine(cfg.OcrEngine, cfg.OcrServiceURL, time.Duration(cfg.OcrTimeout)*time.Second),
+		ruleEngine:     rules.NewEngineFromFile(cfg.RulesConfigPath, cfg.VerdictPolicy),
+		timeValidator:  NewTimeValidator(),
+		oaClient:       client.NewOaBackend(cfg.OaBackend, cfg.OaBaseURL, cfg.OaApiToken, time.Duration(cfg.OaTimeout)*time.Second, cfg.OaMaxRetries),
+	}
+	if cfg.TamperCheckEnabled {
+		s.tamperDetector = NewTamperDetector(cfg.MaxImagePixels)
+	}
+	if cfg.VerdictCacheEnabled {
+		s.verdictCache = NewVerdictCache(cfg.VerdictCacheSize, cfg.VerdictCacheTTL, cfg.VerdictCacheFile)
+	}
+	if cfg.HistoryEnabled {
+		s.history = NewHistoryStore(cfg.HistoryFile, time.Duration(cfg.HistoryRetentionDays)*24*time.Hour)
+	}
+	return s
+}
+
+// Close 写入尚未落盘的判定缓存与申请记录（服务退出时调用）
+func (s *AnalysisService) Close() {
+	if s.verdictCache != nil {
+		s.verdictCache.Close()
+	}
+	if s.history != nil {
+		s.history.Close()
 	}
 }
 
@@ -60,15 +100,14 @@ func (s *AnalysisService) runAnalysis(appData model.ApplicationData, fileHeaders
 	// 1. 检查是否有图片输入
 	hasImages := len(fileHeaders) > 0 || len(appData.ImageUrls) > 0
 
-	// 3. 如果没有图片，检查是否必需
+	// 3. 如果没有图片，由规则引擎按申请类型的规则包裁决（是否必须提供证明材料、必填字段等）
 	if !hasImages {
-		if appData.ApplicationType == "病假" || appData.ApplicationType == "补打卡" {
-			log.Printf("缺少必要图片 - Type: %s", appData.ApplicationType)
-			return &model.AnalysisResult{IsAbnormal: true, Reason: "病假和补打卡申请必须提供证明材料图片"}, nil
-		}
-		// 没有图片且不需要图片，返回正常结果
-		log.Printf("无需图片验证 - Type: %s", appData.ApplicationType)
-		return &model.AnalysisResult{IsAbnormal: false, Reason: "正常"}, nil
+		log.Printf("未提供图片，仅校验申请信息 - Type: %s", appData.ApplicationType)
+		attendance, history := s.FetchAttendance(appData), s.userHistory(appData)
+		result := s.ruleEngine.Validate(appData, attendance, history, nil)
+		s.applyTimeValidation(appData, attendance, result)
+		s.recordHistory(appData, history, result)
+		return result, nil
 	}
 
 	// 2. 准备 AI 分析所需的参数
@@ -80,143 +119,28 @@ func (s *AnalysisService) runAnalysis(appData model.ApplicationData, fileHeaders
 	}
 	attendanceText := strings.Join(appData.AttendanceInfo, ", ")
 
-	// 5. 并发处理多张图片
+	// 4. OA 考勤与图片分析并行获取
+	oaChan := make(chan *model.AttendanceData, 1)
+	go func() { oaChan <- s.FetchAttendance(appData) }()
+
+	// 5. 并发处理多张图片（PDF 证据按页展开为多张图片）
 	var validImageIndex int
 	var imagesAnalysis []model.ImageAnalysisDetail
-	totalImages := len(fileHeaders) + len(appData.ImageUrls)
+	jobs := s.buildImageJobs(fileHeaders, appData.ImageUrls)
+	totalImages := len(jobs)
 
 	log.Printf("开始AI并发分析 - Provider: %s, EmployeeName: %s, 总图片数: %d (文件: %d, URL: %d)",
 		provider, employeeName, totalImages, len(fileHeaders), len(appData.ImageUrls))
 
 	// 使用channel和goroutine并发处理
-	type analysisResult struct {
-		detail        model.ImageAnalysisDetail
-		extractedData *model.ExtractedData
-		index         int
-		err           error
-	}
-
-	resultChan := make(chan analysisResult, totalImages)
+	resultChan := make(chan model.ImageAnalysisDetail, totalImages)
 	var wg sync.WaitGroup
-
-	// 5.1 并发处理上传的文件
-	for i, fileHeader := range fileHeaders {
+	for _, job := range jobs {
 		wg.Add(1)
-		go func(index int, fh *multipart.FileHeader) {
+		go func(job imageJob) {
 			defer wg.Done()
-
-			aiStartTime := time.Now()
-			log.Printf("并发分析第 %d/%d 张图片（文件上传，文件名: %s, 大小: %d bytes）",
-				index+1, totalImages, fh.Filename, fh.Size)
-
-			detail := model.ImageAnalysisDetail{
-				Index:    index + 1,
-				Source:   "file_upload",
-				FileName: fh.Filename,
-			}
-
-			var extractedData *model.ExtractedData
-			var requestId string
-			var tokenUsage *model.TokenUsage
-			var err error
-
-			switch provider {
-			case "qwen":
-				extractedData, requestId, tokenUsage, err = s.qwenClient.ExtractDataFromImage(fh, "", employeeName, appData.ApplicationType, appData.ApplicationDate, appData.StartTime, appData.EndTime)
-			case "volcano":
-				extractedData, requestId, tokenUsage, err = s.volcanoClient.ExtractDataFromImage(fh, "", employeeName, appData.ApplicationType, appData.ApplicationDate, appData.StartTime, appData.EndTime, needImageValidation, attendanceText)
-			default:
-				err = fmt.Errorf("未知的 AI provider: %s", provider)
-			}
-
-			// 设置requestId和tokenUsage
-			detail.RequestId = requestId
-			detail.TokenUsage = tokenUsage
-
-			aiDuration := time.Since(aiStartTime)
-			detail.ProcessingTimeMs = aiDuration.Milliseconds()
-
-			if err != nil {
-				detail.Success = false
-				detail.ErrorMessage = err.Error()
-				detail.IsValid = false
-				log.Printf("✗ 第 %d 张图片分析失败 (耗时: %v): %v", index+1, aiDuration, err)
-			} else {
-				// 分析成功
-				detail.Success = true
-				detail.ExtractedData = extractedData
-				detail.IsValid = extractedData.IsProofTypeValid
-				log.Printf("第 %d 张图片分析完成 (耗时: %v): IsProofTypeValid=%v, ExtractedName=%s, RequestType=%s",
-					index+1, aiDuration, extractedData.IsProofTypeValid, extractedData.ExtractedName, extractedData.RequestType)
-			}
-
-			resultChan <- analysisResult{
-				detail:        detail,
-				extractedData: extractedData,
-				index:         index,
-				err:           err,
-			}
-		}(i, fileHeader)
-	}
-
-	// 5.2 并发处理 URL 图片
-	for i, imageURL := range appData.ImageUrls {
-		wg.Add(1)
-		go func(index int, url string) {
-			defer wg.Done()
-
-			imageIndex := len(fileHeaders) + index + 1
-			aiStartTime := time.Now()
-			log.Printf("并发分析第 %d/%d 张图片（URL直传: %s）", imageIndex, totalImages, url)
-
-			detail := model.ImageAnalysisDetail{
-				Index:    imageIndex,
-				Source:   "url_download",
-				ImageURL: url,
-			}
-
-			var extractedData *model.ExtractedData
-			var requestId string
-			var tokenUsage *model.TokenUsage
-			var err error
-
-			switch provider {
-			case "qwen":
-				extractedData, requestId, tokenUsage, err = s.qwenClient.ExtractDataFromImage(nil, url, employeeName, appData.ApplicationType, appData.ApplicationDate, appData.StartTime, appData.EndTime)
-			case "volcano":
-				extractedData, requestId, tokenUsage, err = s.volcanoClient.ExtractDataFromImage(nil, url, employeeName, appData.ApplicationType, appData.ApplicationDate, appData.StartTime, appData.EndTime, needImageValidation, attendanceText)
-			default:
-				err = fmt.Errorf("未知的 AI provider: %s", provider)
-			}
-
-			// 设置requestId和tokenUsage
-			detail.RequestId = requestId
-			detail.TokenUsage = tokenUsage
-
-			aiDuration := time.Since(aiStartTime)
-			detail.ProcessingTimeMs = aiDuration.Milliseconds()
-
-			if err != nil {
-				detail.Success = false
-				detail.ErrorMessage = err.Error()
-				detail.IsValid = false
-				log.Printf("✗ 第 %d 张图片分析失败 (耗时: %v): %v", imageIndex, aiDuration, err)
-			} else {
-				// 分析成功
-				detail.Success = true
-				detail.ExtractedData = extractedData
-				detail.IsValid = extractedData.IsProofTypeValid
-				log.Printf("第 %d 张图片分析完成 (耗时: %v): IsProofTypeValid=%v, ExtractedName=%s, RequestType=%s",
-					imageIndex, aiDuration, extractedData.IsProofTypeValid, extractedData.ExtractedName, extractedData.RequestType)
-			}
-
-			resultChan <- analysisResult{
-				detail:        detail,
-				extractedData: extractedData,
-				index:         len(fileHeaders) + index,
-				err:           err,
-			}
-		}(i, imageURL)
+			resultChan <- s.analyzeImage(job, totalImages, provider, appData, needImageValidation, attendanceText)
+		}(job)
 	}
 
 	// 等待所有goroutine完成
@@ -226,13 +150,13 @@ func (s *AnalysisService) runAnalysis(appData model.ApplicationData, fileHeaders
 	}()
 
 	// 收集结果
-	for result := range resultChan {
-		imagesAnalysis = append(imagesAnalysis, result.detail)
+	for detail := range resultChan {
+		imagesAnalysis = append(imagesAnalysis, detail)
 
 		// 检查是否满足条件（证明材料类型有效）
-		if validImageIndex == 0 && result.extractedData != nil && result.extractedData.IsProofTypeValid {
-			validImageIndex = result.detail.Index
-			log.Printf("✓ 第 %d 张图片满足条件", result.detail.Index)
+		if validImageIndex == 0 && detail.ExtractedData != nil && detail.ExtractedData.IsProofTypeValid {
+			validImageIndex = detail.Index
+			log.Printf("✓ 第 %d 张图片满足条件", detail.Index)
 		}
 	}
 
@@ -259,20 +183,401 @@ func (s *AnalysisService) runAnalysis(appData model.ApplicationData, fileHeaders
 		}
 	}
 
-	result := rules.ValidateApplication(appData, nil, allExtractedData)
+	attendance, history := <-oaChan, s.userHistory(appData)
+	result := s.ruleEngine.Validate(appData, attendance, history, allExtractedData)
 	rulesDuration := time.Since(rulesStartTime)
 
 	// 8. 添加详细分析结果
 	result.ValidImageIndex = validImageIndex
 	result.ImagesAnalysis = imagesAnalysis
 
+	// 部分图片处理失败时，通过结论转人工复核（失败的图片可能包含相反证据）
+	if failed := len(imagesAnalysis) - len(allExtractedData); failed > 0 && result.Verdict == model.VerdictApprove {
+		rules.FlagForReview(result, fmt.Sprintf("%d 张图片处理失败，未参与裁决", failed))
+	}
+	s.applyTimeValidation(appData, attendance, result)
+	s.recordHistory(appData, history, result)
+
 	totalDuration := time.Since(startTime)
 	log.Printf("规则引擎验证完成 (耗时: %v)", rulesDuration)
-	log.Printf("总分析时间: %v, 结果: IsAbnormal=%v, Reason=%s",
-		totalDuration, result.IsAbnormal, result.Reason)
+	log.Printf("总分析时间: %v, 结果: IsAbnormal=%v, Verdict=%s, Reason=%s",
+		totalDuration, result.IsAbnormal, result.Verdict, result.Reason)
 
 	return result, nil
 }
 
-// GetEmployeeData 获取员工考勤数据
-// 已移除 OA 客户端相关逻辑，不再提供远程获取员工考勤数据的接口
+// ValidateTime 时间校验（工作日、迟到早退、风险级别），attendance 为 OA 当天考勤（可为 nil），校验失败时返回 nil
+func (s *AnalysisService) ValidateTime(appData model.ApplicationData, attendance *model.AttendanceData) *model.TimeValidationResult {
+	tv, err := s.timeValidator.ValidateApplicationTime(appData, attendance)
+	if err != nil {
+		log.Printf("时间校验失败: %v", err)
+		return nil
+	}
+	return tv
+}
+
+// applyTimeValidation 时间校验结果写入分析结果，高风险时通过结论转人工复核
+func (s *AnalysisService) applyTimeValidation(appData model.ApplicationData, attendance *model.AttendanceData, result *model.AnalysisResult) {
+	tv := s.ValidateTime(appData, attendance)
+	if tv == nil {
+		return
+	}
+	result.TimeValidation = tv
+	if tv.RiskLevel == "high" && result.Verdict == model.VerdictApprove {
+		rules.FlagForReview(result, "时间校验高风险: "+tv.Suggestion)
+	}
+}
+
+// userHistory 申请人的历史申请记录，未启用申请记录或缺少员工ID时返回 nil
+func (s *AnalysisService) userHistory(appData model.ApplicationData) []model.HistoryRecord {
+	if s.history == nil || appData.UserId == "" {
+		return nil
+	}
+	return s.history.Records(appData.UserId)
+}
+
+// recordHistory 将近期申请记录摘要写入分析结果，并记录本次申请及其结论
+func (s *AnalysisService) recordHistory(appData model.ApplicationData, history []model.HistoryRecord, result *model.AnalysisResult) {
+	if history == nil {
+		return
+	}
+	result.History = rules.SummarizeHistory(appData, history, historySummaryDays, historyRecentLimit)
+	if record, ok := rules.HistoryRecordFor(appData, result.Verdict, time.Now()); ok {
+		s.history.Add(record)
+	}
+}
+
+// applicationDateText 发送给 LLM 的申请日期：多日申请为 "开始~结束"，否则为申请日期
+func applicationDateText(appData model.ApplicationData) string {
+	switch {
+	case appData.StartDate != "" && appData.EndDate != "" && appData.StartDate != appData.EndDate:
+		return appData.StartDate + "~" + appData.EndDate
+	case appData.StartDate != "":
+		return appData.StartDate
+	case appData.EndDate != "":
+		return appData.EndDate
+	}
+	return appData.ApplicationDate
+}
+
+// imageJob 单张图片的分析任务
+type imageJob struct {
+	index      int                   // 图片索引（从1开始）
+	source     string                // 来源：file_upload 或 url_download
+	fileHeader *multipart.FileHeader // 文件上传时的文件
+	imageURL   string                // 发送给 LLM 的地址（URL 或 data URI）
+	fileName   string                // 文件名（文件上传时）
+	originURL  string                // 原始 URL（URL 下载时）
+	page       int                   // PDF 页码（非 PDF 为 0）
+	err        error                 // 预处理失败原因（如 PDF 解析失败），非空时不再调用 LLM
+}
+
+// maxPdfBytes PDF 证据读取的大小上限
+const maxPdfBytes = 50 << 20
+
+// buildImageJobs 构建图片任务列表（文件上传在前，URL 在后，索引从1开始）
+// PDF 证据（文件或 URL）提取前 N 页的图片，每页作为一张独立图片参与分析
+func (s *AnalysisService) buildImageJobs(fileHeaders []*multipart.FileHeader, imageUrls []string) []imageJob {
+	var jobs []imageJob
+	for _, fh := range fileHeaders {
+		if isPdfFile(fh) {
+			data, err := client.LoadImageBytes(fh, "", maxPdfBytes)
+			jobs = append(jobs, s.pdfPageJobs(data, err, imageJob{source: "file_upload", fileName: fh.Filename})...)
+			continue
+		}
+		jobs = append(jobs, imageJob{source: "file_upload", fileHeader: fh, fileName: fh.Filename})
+	}
+	for _, url := range imageUrls {
+		if client.IsPdfURL(url) {
+			data, err := client.LoadImageBytes(nil, url, s.readLimit(maxPdfBytes))
+			jobs = append(jobs, s.pdfPageJobs(data, err, imageJob{source: "url_download", originURL: url})...)
+			continue
+		}
+		jobs = append(jobs, imageJob{source: "url_download", imageURL: url, originURL: url})
+	}
+	for i := range jobs {
+		jobs[i].index = i + 1
+	}
+	return jobs
+}
+
+// pdfPageJobs 将一份 PDF 展开为逐页任务；整份解析失败时返回一个携带错误的任务
+func (s *AnalysisService) pdfPageJobs(data []byte, loadErr error, base imageJob) []imageJob {
+	if loadErr != nil {
+		base.err = fmt.Errorf("读取 PDF 失败: %w", loadErr)
+		return []imageJob{base}
+	}
+	pages, err := client.ExtractPdfPageImages(data, s.pdfMaxPages, s.maxImagePixels)
+	if err != nil {
+		base.err = fmt.Errorf("解析 PDF 失败: %w", err)
+		return []imageJob{base}
+	}
+	jobs := make([]imageJob, 0, len(pages))
+	for _, page := range pages {
+		job := base
+		job.page = page.Page
+		job.imageURL = page.DataURI
+		job.err = page.Err
+		jobs = append(jobs, job)
+	}
+	log.Printf("PDF 证据 %s%s 展开为 %d 页", base.fileName, base.originURL, len(jobs))
+	return jobs
+}
+
+// isPdfFile 根据文件名、Content-Type 或文件头判断上传文件是否为 PDF
+func isPdfFile(fh *multipart.FileHeader) bool {
+	if strings.HasSuffix(strings.ToLower(fh.Filename), ".pdf") || fh.Header.Get("Content-Type") == "application/pdf" {
+		return true
+	}
+	file, err := fh.Open()
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	head := make([]byte, 1024)
+	n, _ := file.Read(head)
+	return client.IsPdf(head[:n])
+}
+
+// maxImageReadBytes 未配置 MAX_UPLOAD_FILE_BYTES 时读取图片原始字节（缓存哈希、OCR、增强、篡改检测共用）的大小上限
+const maxImageReadBytes = 20 << 20
+
+// readLimit 读取图片/PDF 的字节上限：不超过 fallback，配置了单张图片上限时取两者较小值
+// 上传文件与 base64 图片已在请求入口校验，URL 图片在下载时按同一上限校验
+func (s *AnalysisService) readLimit(fallback int64) int64 {
+	if s.maxImageBytes > 0 && s.maxImageBytes < fallback {
+		return s.maxImageBytes
+	}
+	return fallback
+}
+
+// loadImageBytes 读取图片原始字节并在解码前校验像素数
+func (s *AnalysisService) loadImageBytes(job imageJob) ([]byte, error) {
+	data, err := client.LoadImageBytes(job.fileHeader, job.imageURL, s.readLimit(maxImageReadBytes))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.CheckImagePixels(data, s.maxImagePixels); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// analyzeImage 分析单张图片：调用 LLM 提取数据，同时进行非 LLM 的篡改检测
+func (s *AnalysisService) analyzeImage(job imageJob, totalImages int, provider string, appData model.ApplicationData, needImageValidation bool, attendanceText string) model.ImageAnalysisDetail {
+	aiStartTime := time.Now()
+	switch {
+	case job.page > 0:
+		log.Printf("并发分析第 %d/%d 张图片（PDF %s%s 第 %d 页）", job.index, totalImages, job.fileName, job.originURL, job.page)
+	case job.fileHeader != nil:
+		log.Printf("并发分析第 %d/%d 张图片（文件上传，文件名: %s, 大小: %d bytes）",
+			job.index, totalImages, job.fileHeader.Filename, job.fileHeader.Size)
+	default:
+		log.Printf("并发分析第 %d/%d 张图片（URL直传: %s）", job.index, totalImages, job.imageURL)
+	}
+
+	detail := model.ImageAnalysisDetail{
+		Index:    job.index,
+		Source:   job.source,
+		FileName: job.fileName,
+		ImageURL: job.originURL,
+		Page:     job.page,
+	}
+	if job.err != nil {
+		detail.ErrorMessage = job.err.Error()
+		log.Printf("✗ 第 %d 张图片预处理失败: %v", job.index, job.err)
+		return detail
+	}
+
+	// 图片原始字节每个任务只读取一次（URL 图片只下载一次），供缓存哈希、OCR、增强与篡改检测共用
+	// 超过字节或像素上限的图片（URL 图片在此首次校验）不再调用 LLM
+	data, loadErr := s.loadImageBytes(job)
+	if errors.Is(loadErr, client.ErrImageTooLarge) {
+		detail.ErrorMessage = loadErr.Error()
+		log.Printf("✗ 第 %d 张图片超过上限: %v", job.index, loadErr)
+		return detail
+	}
+	if loadErr != nil {
+		log.Printf("第 %d 张图片读取失败，跳过缓存、OCR、增强与篡改检测: %v", job.index, loadErr)
+	}
+
+	// 篡改检测与 LLM 调用并行执行
+	var tamperChan chan *model.TamperCheckResult
+	if s.tamperDetector != nil {
+		tamperChan = make(chan *model.TamperCheckResult, 1)
+		go func() {
+			tamperChan <- s.inspectTamper(data, loadErr)
+		}()
+	}
+
+	var extractedData *model.ExtractedData
+	var requestId string
+	var tokenUsage *model.TokenUsage
+	var err error
+
+	// 相同图片与相同申请参数直接复用历史判定，不再调用 LLM
+	cacheKey := s.verdictCacheKey(data, provider, appData, needImageValidation)
+	if cacheKey != "" {
+		if cached, cachedRequestId, ok := s.verdictCache.Get(cacheKey); ok {
+			log.Printf("第 %d 张图片命中判定缓存 (原请求ID: %s)", job.index, cachedRequestId)
+			extractedData, requestId = cached, cachedRequestId
+			detail.CacheHit = true
+		}
+	}
+
+	// 未命中缓存时先做 OCR 预识别（结果写入 Prompt），再按申请类型执行可选的图片增强，增强后的图片以 data URI 发送给 LLM
+	llmJob := job
+	var ocrResult *client.OcrResult
+	if !detail.CacheHit && loadErr == nil {
+		ocrResult = s.recognizeText(job.index, data)
+		llmJob, detail.Enhancements = s.enhanceImage(job, data, appData.ApplicationType)
+	}
+	ocrHint := client.BuildOcrHint(ocrResult)
+
+	employeeName := appData.Alias
+	appDate := applicationDateText(appData)
+	switch {
+	case detail.CacheHit:
+	case provider == "qwen":
+		extractedData, requestId, tokenUsage, err = s.qwenClient.ExtractDataFromImage(llmJob.fileHeader, llmJob.imageURL, employeeName, appData.ApplicationType, appDate, appData.StartTime, appData.EndTime, ocrHint)
+	case provider == "volcano":
+		extractedData, requestId, tokenUsage, err = s.volcanoClient.ExtractDataFromImage(llmJob.fileHeader, llmJob.imageURL, employeeName, appData.ApplicationType, appDate, appData.StartTime, appData.EndTime, needImageValidation, attendanceText, ocrHint)
+	default:
+		err = fmt.Errorf("未知的 AI provider: %s", provider)
+	}
+	if err == nil && ocrResult != nil {
+		extractedData.OcrDates = ocrResult.Dates
+		extractedData.OcrTimes = ocrResult.Times
+		detail.OcrLines = ocrResult.Lines
+	}
+	if err == nil && cacheKey != "" && !detail.CacheHit {
+		s.verdictCache.Put(cacheKey, extractedData, requestId)
+	}
+
+	// 设置requestId和tokenUsage
+	detail.RequestId = requestId
+	detail.TokenUsage = tokenUsage
+
+	aiDuration := time.Since(aiStartTime)
+	detail.ProcessingTimeMs = aiDuration.Milliseconds()
+
+	if err != nil {
+		detail.Success = false
+		detail.ErrorMessage = err.Error()
+		detail.IsValid = false
+		log.Printf("✗ 第 %d 张图片分析失败 (耗时: %v): %v", job.index, aiDuration, err)
+	} else {
+		// 分析成功
+		detail.Success = true
+		detail.ExtractedData = extractedData
+		detail.IsValid = extractedData.IsProofTypeValid
+		log.Printf("第 %d 张图片分析完成 (耗时: %v): IsProofTypeValid=%v, ExtractedName=%s, RequestType=%s",
+			job.index, aiDuration, extractedData.IsProofTypeValid, extractedData.ExtractedName, extractedData.RequestType)
+	}
+
+	if tamperChan != nil {
+		detail.TamperCheck = <-tamperChan
+		if detail.ExtractedData != nil {
+			detail.ExtractedData.TamperRiskScore = detail.TamperCheck.RiskScore
+			detail.ExtractedData.TamperEditMarker = detail.TamperCheck.EditMarker
+		}
+	}
+	if detail.ExtractedData != nil {
+		detail.ExtractedData.ImageIndex = job.index
+	}
+
+	return detail
+}
+
+// verdictCacheKey 计算判定缓存键：按图片内容哈希（URL 图片同样按下载到的内容，避免同一 URL 换图后命中旧判定）
+// 缓存关闭或未能读取图片时返回空串
+func (s *AnalysisService) verdictCacheKey(data []byte, provider string, appData model.ApplicationData, needImageValidation bool) string {
+	if s.verdictCache == nil || data == nil {
+		return ""
+	}
+	imageID := ContentHash(data)
+
+	var modelName, promptVersion string
+	switch provider {
+	case "qwen":
+		modelName, promptVersion = client.QwenVisionModel, client.QwenPromptVersion
+	case "volcano":
+		modelName, promptVersion = client.VolcanoVisionModel, client.VolcanoPromptVersion
+	default:
+		return ""
+	}
+	// 增强方案会改变送入 LLM 的图片，需要参与缓存键
+	if transforms := client.EnhanceProfileFor(s.enhanceProfile, appData.ApplicationType); len(transforms) > 0 {
+		promptVersion += "|enhance:" + strings.Join(transforms, ",")
+	}
+	// OCR 结果会写入 Prompt 并参与规则核对
+	if s.ocrEngine != nil {
+		promptVersion += "|ocr"
+	}
+	return VerdictCacheKey(imageID, provider, modelName, promptVersion, appData, needImageValidation)
+}
+
+// enhanceImage 按申请类型执行图片增强，返回用于 LLM 调用的任务与实际生效的变换
+// 未配置增强或增强失败时返回原任务（失败仅记录日志，不影响分析）
+func (s *AnalysisService) enhanceImage(job imageJob, data []byte, appType string) (imageJob, []string) {
+	transforms := client.EnhanceProfileFor(s.enhanceProfile, appType)
+	if len(transforms) == 0 {
+		return job, nil
+	}
+	dataURI, applied, err := client.EnhanceImageBytes(data, transforms, s.maxImagePixels)
+	if err != nil {
+		log.Printf("第 %d 张图片增强失败，使用原图: %v", job.index, err)
+		return job, nil
+	}
+	enhanced := job
+	enhanced.fileHeader = nil
+	enhanced.imageURL = dataURI
+	return enhanced, applied
+}
+
+// recognizeText 使用原图进行 OCR 预识别，关闭或失败时返回 nil（失败仅记录日志，不影响分析）
+func (s *AnalysisService) recognizeText(index int, data []byte) *client.OcrResult {
+	if s.ocrEngine == nil {
+		return nil
+	}
+	result, err := s.ocrEngine.Recognize(data)
+	if err != nil {
+		log.Printf("第 %d 张图片 OCR 失败: %v", index, err)
+		return nil
+	}
+	return result
+}
+
+// inspectTamper 对图片原始字节进行篡改检测，读取失败时返回未检测的结果
+func (s *AnalysisService) inspectTamper(data []byte, loadErr error) *model.TamperCheckResult {
+	if loadErr != nil {
+		return &model.TamperCheckResult{RiskLevel: "low", ErrorMessage: loadErr.Error()}
+	}
+	return s.tamperDetector.Inspect(data)
+}
+
+// FetchAttendance 从 OA 获取申请人申请日期当天的考勤，供规则引擎与时间校验核对；未接入 OA、缺少员工ID/申请日期或获取失败时返回 nil
+func (s *AnalysisService) FetchAttendance(appData model.ApplicationData) *model.AttendanceData {
+	if s.oaClient == nil || appData.UserId == "" || appData.ApplicationDate == "" {
+		return nil
+	}
+	data, err := s.oaClient.GetAttendanceData(appData.UserId, appData.ApplicationDate)
+	if err != nil {
+		log.Printf("获取 OA 考勤失败，按无考勤数据处理 - UserId: %s, Date: %s: %v", appData.UserId, appData.ApplicationDate, err)
+		return nil
+	}
+	return data
+}
+
+// LookupEmployee 从 OA 获取员工基准数据，未接入 OA 或获取失败时返回 nil
+func (s *AnalysisService) LookupEmployee(userID string) *client.EmployeeData {
+	if s.oaClient == nil || userID == "" {
+		return nil
+	}
+	data, err := s.oaClient.GetEmployeeData(userID)
+	if err != nil {
+		log.Printf("获取 OA 员工数据失败 - UserId: %s: %v", userID, err)
+		return nil
+	}
+	return data
+}
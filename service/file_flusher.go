@@ -0,0 +1,116 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// persistFlushInterval 落盘合并间隔：间隔内的多次写入只落盘一次（进程异常退出时最多丢失该间隔内的变更）
+const persistFlushInterval = 2 * time.Second
+
+// fileFlusher 合并落盘：写入时只标记有变更，由后台 goroutine 按间隔将最新快照写入 JSON 文件，
+// 避免每次请求都在请求路径上重写整个文件；Close 时同步写入尚未落盘的变更
+type fileFlusher struct {
+	path     string
+	label    string             // 日志中的名称，如 "判定缓存"
+	snapshot func() interface{} // 生成落盘内容，由持有者自行加锁
+	interval time.Duration
+
+	dirty     chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// newFileFlusher 创建并启动后台落盘
+func newFileFlusher(path, label string, interval time.Duration, snapshot func() interface{}) *fileFlusher {
+	f := &fileFlusher{
+		path:     path,
+		label:    label,
+		snapshot: snapshot,
+		interval: interval,
+		dirty:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// MarkDirty 标记有变更，不阻塞
+func (f *fileFlusher) MarkDirty() {
+	select {
+	case f.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// Close 停止后台落盘并写入尚未落盘的变更
+func (f *fileFlusher) Close() {
+	f.closeOnce.Do(func() {
+		close(f.done)
+		<-f.stopped
+	})
+}
+
+func (f *fileFlusher) run() {
+	defer close(f.stopped)
+	for {
+		select {
+		case <-f.dirty:
+		case <-f.done:
+			f.flushPending()
+			return
+		}
+		timer := time.NewTimer(f.interval)
+		select {
+		case <-timer.C:
+		case <-f.done:
+			timer.Stop()
+		}
+		f.flush()
+	}
+}
+
+// flushPending 退出前写入已标记但未落盘的变更
+func (f *fileFlusher) flushPending() {
+	select {
+	case <-f.dirty:
+		f.flush()
+	default:
+	}
+}
+
+func (f *fileFlusher) flush() {
+	// 本次快照已包含此前的全部变更
+	select {
+	case <-f.dirty:
+	default:
+	}
+	if err := writeJSONFile(f.path, f.snapshot()); err != nil {
+		log.Printf("%s落盘失败: %v", f.label, err)
+	}
+}
+
+// writeJSONFile 先写临时文件再重命名，避免写入中途崩溃导致文件损坏
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("序列化失败: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("创建目录失败: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"my-ai-app/model"
+	"my-ai-app/rules"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 近期申请记录摘要的统计天数与展示条数
+const (
+	historySummaryDays = 90
+	historyRecentLimit = 10
+)
+
+// HistoryStore 员工历史申请记录（按 UserId），用于申请频次核对与近期记录摘要
+// 内存保存，超过保留期的记录自动清理；可选落盘到 JSON 文件以便重启后保留（后台合并落盘，见 fileFlusher）
+type HistoryStore struct {
+	mu        sync.Mutex
+	retention time.Duration
+	filePath  string // 为空表示不落盘
+	records   map[string][]model.HistoryRecord
+
+	flusher *fileFlusher // 为 nil 表示不落盘
+}
+
+// NewHistoryStore 创建申请记录存储，filePath 非空时从文件恢复保留期内的记录
+func NewHistoryStore(filePath string, retention time.Duration) *HistoryStore {
+	h := &HistoryStore{
+		retention: retention,
+		filePath:  filePath,
+		records:   make(map[string][]model.HistoryRecord),
+	}
+	if filePath != "" {
+		if err := h.load(); err != nil {
+			log.Printf("加载申请记录文件失败: %v", err)
+		}
+		h.flusher = newFileFlusher(filePath, "申请记录", persistFlushInterval, func() interface{} {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			return h.snapshotLocked(time.Now())
+		})
+	}
+	return h
+}
+
+// Close 写入尚未落盘的申请记录（服务退出时调用）
+func (h *HistoryStore) Close() {
+	if h.flusher != nil {
+		h.flusher.Close()
+	}
+}
+
+// Records 返回员工保留期内的申请记录副本（按记录时间由旧到新），无记录时返回空切片
+func (h *HistoryStore) Records(userID string) []model.HistoryRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := []model.HistoryRecord{}
+	for _, r := range h.records[userID] {
+		if !h.expired(r, time.Now()) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Add 写入一条申请记录：同一申请重复提交时替换旧记录，并清理该员工过期的记录
+func (h *HistoryStore) Add(record model.HistoryRecord) {
+	if record.UserId == "" {
+		return
+	}
+	h.mu.Lock()
+	now := time.Now()
+	kept := make([]model.HistoryRecord, 0, len(h.records[record.UserId])+1)
+	for _, r := range h.records[record.UserId] {
+		if h.expired(r, now) || rules.SameApplication(r, record) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	h.records[record.UserId] = append(kept, record)
+	h.mu.Unlock()
+
+	if h.flusher != nil {
+		h.flusher.MarkDirty()
+	}
+}
+
+// expired 记录是否超过保留期（retention <= 0 表示永久保留）
+func (h *HistoryStore) expired(r model.HistoryRecord, now time.Time) bool {
+	return h.retention > 0 && now.Sub(r.RecordedAt) > h.retention
+}
+
+// snapshotLocked 复制全部未过期记录（调用方需持有锁）
+func (h *HistoryStore) snapshotLocked(now time.Time) []model.HistoryRecord {
+	var out []model.HistoryRecord
+	for _, list := range h.records {
+		for _, r := range list {
+			if !h.expired(r, now) {
+				out = append(out, r)
+			}
+		}
+	}
+	return out
+}
+
+// load 从文件恢复申请记录
+func (h *HistoryStore) load() error {
+	data, err := os.ReadFile(h.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var records []model.HistoryRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("解析申请记录文件失败: %w", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	count := 0
+	for _, r := range records {
+		if r.UserId == "" || h.expired(r, now) {
+			continue
+		}
+		h.records[r.UserId] = append(h.records[r.UserId], r)
+		count++
+	}
+	for _, list := range h.records {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].RecordedAt.Before(list[j].RecordedAt) })
+	}
+	log.Printf("申请记录已从 %s 恢复 %d 条", h.filePath, count)
+	return nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"my-ai-app/model"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryStoreReplacesAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	h := NewHistoryStore(path, 400*24*time.Hour)
+	record := model.HistoryRecord{UserId: "u1", ApplicationType: "补打卡", Date: "2025-10-15", EndTime: "18:30", Verdict: model.VerdictNeedsReview, RecordedAt: time.Now()}
+	h.Add(record)
+	record.Verdict = model.VerdictApprove
+	h.Add(record) // 重复提交替换旧记录
+	h.Add(model.HistoryRecord{UserId: "u1", ApplicationType: "补打卡", Date: "2025-10-16", EndTime: "18:30", Verdict: model.VerdictApprove, RecordedAt: time.Now()})
+	h.Close()
+
+	reloaded := NewHistoryStore(path, 400*24*time.Hour)
+	defer reloaded.Close()
+	records := reloaded.Records("u1")
+	if len(records) != 2 || records[0].Verdict != model.VerdictApprove {
+		t.Fatalf("重启后申请记录 = %+v, want 2 条且重复提交以最新结论为准", records)
+	}
+}
@@ -0,0 +1,635 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"log"
+	"math"
+	"my-ai-app/model"
+	"sort"
+	"strings"
+	"time"
+
+	_ "image/gif"
+	_ "image/png"
+)
+
+// TamperDetector 图片篡改/编辑痕迹检测器
+// 纯 Go 实现的非 LLM 取证：元数据中的编辑软件标记、JPEG 二次压缩、时间戳区域的误差水平分析（ELA）
+type TamperDetector struct {
+	maxELAPixels    int // 超过该像素数的图片跳过 ELA，避免耗时过长
+	maxDecodePixels int // 超过该像素数的图片不做像素级分析，防止解压炸弹
+}
+
+// NewTamperDetector 创建篡改检测器，maxDecodePixels<=0 表示不限制
+func NewTamperDetector(maxDecodePixels int) *TamperDetector {
+	return &TamperDetector{maxELAPixels: 25_000_000, maxDecodePixels: maxDecodePixels}
+}
+
+// 各类信号的权重，最终风险分按 1-∏(1-w) 合成
+const (
+	weightEditorSoftware   = 0.5
+	weightXmpHistory       = 0.3
+	weightPhotoshopIRB     = 0.2
+	weightExifTimeMismatch = 0.2
+	weightDoubleCompress   = 0.35
+	weightGridMisaligned   = 0.3
+	weightTimestampELA     = 0.4
+)
+
+// editorSoftwareMarkers 常见图片编辑软件在元数据中的标识（小写匹配）
+var editorSoftwareMarkers = []string{
+	"photoshop", "gimp", "lightroom", "pixelmator", "snapseed", "picsart", "canva",
+	"paint.net", "affinity", "fotor", "polarr", "photoscape", "acdsee",
+	"meitu", "美图", "醒图", "beautycam", "b612",
+}
+
+// Inspect 对图片原始字节进行取证分析
+func (td *TamperDetector) Inspect(data []byte) *model.TamperCheckResult {
+	startTime := time.Now()
+	result := &model.TamperCheckResult{RiskLevel: "low"}
+
+	var weights []float64
+	addSignal := func(weight float64, format string, args ...interface{}) {
+		weights = append(weights, weight)
+		result.Signals = append(result.Signals, fmt.Sprintf(format, args...))
+	}
+	// 元数据/编辑软件痕迹；二次压缩、网格错位、ELA 异常在经微信/钉钉转发的截图中同样常见，不计入
+	addMarker := func(weight float64, format string, args ...interface{}) {
+		result.EditMarker = true
+		addSignal(weight, format, args...)
+	}
+
+	switch {
+	case len(data) >= 3 && data[0] == 0xFF && data[1] == 0xD8:
+		result.Format = "jpeg"
+		info := parseJpegSegments(data)
+		td.inspectMetadata(info.exif, info.xmp, result, addMarker)
+		if info.photoshopIRB {
+			addMarker(weightPhotoshopIRB, "包含 Photoshop 资源块(APP13)")
+		}
+
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err == nil && td.maxDecodePixels > 0 && cfg.Width*cfg.Height > td.maxDecodePixels {
+			result.ErrorMessage = fmt.Sprintf("图片尺寸 %dx%d 超过像素上限，跳过像素级分析", cfg.Width, cfg.Height)
+			break
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			result.ErrorMessage = fmt.Sprintf("无法解码图片: %v", err)
+			break
+		}
+		luma, w, h := lumaPlane(img)
+		if luma == nil {
+			break
+		}
+		if table, ok := info.quant[info.lumaTableID]; ok {
+			result.EstimatedQuality = estimateJpegQuality(table)
+			if holes := doubleQuantizationScore(luma, w, h, table); holes >= 3 {
+				addSignal(weightDoubleCompress, "DCT 系数直方图呈周期性缺口（疑似二次压缩，异常点 %d）", holes)
+			}
+		}
+		if dx, dy, ok := gridMisalignment(luma, w, h); ok {
+			addSignal(weightGridMisaligned, "8x8 压缩网格错位 (偏移 %d,%d)，疑似裁剪后重新保存", dx, dy)
+		}
+		if w*h <= td.maxELAPixels {
+			for _, region := range timestampELAAnomalies(img, luma, w, h) {
+				addSignal(weightTimestampELA, "%s时间戳区域误差水平异常（疑似局部修改）", region)
+			}
+		}
+	case len(data) >= 8 && bytes.Equal(data[:8], []byte("\x89PNG\r\n\x1a\n")):
+		result.Format = "png"
+		software, xmp := parsePngText(data)
+		if software != "" {
+			result.Software = software
+			if marker := matchEditorSoftware(software); marker != "" {
+				addMarker(weightEditorSoftware, "元数据显示经编辑软件处理: %s", software)
+			}
+		}
+		td.inspectMetadata(nil, xmp, result, addMarker)
+	default:
+		_, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			result.ErrorMessage = fmt.Sprintf("无法识别图片格式: %v", err)
+			return result
+		}
+		result.Format = format
+	}
+
+	result.Checked = result.ErrorMessage == ""
+	score := 1.0
+	for _, w := range weights {
+		score *= 1 - w
+	}
+	result.RiskScore = math.Round((1-score)*100) / 100
+	switch {
+	case result.RiskScore >= 0.7 && result.EditMarker:
+		result.RiskLevel = "high"
+	case result.RiskScore >= 0.4:
+		result.RiskLevel = "medium"
+	}
+
+	log.Printf("篡改检测完成 (耗时: %v) - 格式: %s, 风险分: %.2f, 信号: %v",
+		time.Since(startTime), result.Format, result.RiskScore, result.Signals)
+	return result
+}
+
+// inspectMetadata 检查 EXIF / XMP 中的编辑痕迹
+func (td *TamperDetector) inspectMetadata(exif []byte, xmp []byte, result *model.TamperCheckResult, addSignal func(float64, string, ...interface{})) {
+	if len(exif) > 0 {
+		tags := parseExifTags(exif)
+		if software := tags[exifTagSoftware]; software != "" {
+			result.Software = software
+			if matchEditorSoftware(software) != "" {
+				addSignal(weightEditorSoftware, "元数据显示经编辑软件处理: %s", software)
+			}
+		}
+		modified, errM := time.Parse("2006:01:02 15:04:05", tags[exifTagDateTime])
+		original, errO := time.Parse("2006:01:02 15:04:05", tags[exifTagDateTimeOriginal])
+		if errM == nil && errO == nil && modified.Sub(original) > time.Minute {
+			addSignal(weightExifTimeMismatch, "EXIF 修改时间 %s 晚于拍摄时间 %s",
+				tags[exifTagDateTime], tags[exifTagDateTimeOriginal])
+		}
+	}
+
+	if len(xmp) > 0 {
+		lower := strings.ToLower(string(xmp))
+		if result.Software == "" || matchEditorSoftware(result.Software) == "" {
+			if marker := matchEditorSoftware(lower); marker != "" {
+				addSignal(weightEditorSoftware, "XMP 元数据包含编辑软件标识: %s", marker)
+			}
+		}
+		if strings.Contains(lower, "xmpmm:history") || strings.Contains(lower, "photoshop:history") {
+			addSignal(weightXmpHistory, "XMP 元数据包含编辑历史记录")
+		}
+	}
+}
+
+// matchEditorSoftware 返回命中的编辑软件标识，未命中返回空串
+func matchEditorSoftware(s string) string {
+	lower := strings.ToLower(s)
+	for _, marker := range editorSoftwareMarkers {
+		if strings.Contains(lower, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// --- JPEG 结构解析 ---
+
+type jpegInfo struct {
+	quant        map[int][64]int // 量化表（自然顺序），按表 ID 索引
+	lumaTableID  int             // 亮度分量使用的量化表 ID
+	exif         []byte          // APP1 Exif 数据（不含 "Exif\0\0" 头）
+	xmp          []byte          // APP1 XMP 数据
+	photoshopIRB bool            // 是否包含 APP13 Photoshop 资源块
+}
+
+// jpegUnzigzag 之字形序号到自然顺序的映射
+var jpegUnzigzag = [64]int{
+	0, 1, 8, 16, 9, 2, 3, 10, 17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34, 27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36, 29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46, 53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// jpegStdLumaQuant IJG 标准亮度量化表（自然顺序，质量 50）
+var jpegStdLumaQuant = [64]int{
+	16, 11, 10, 16, 24, 40, 51, 61,
+	12, 12, 14, 19, 26, 58, 60, 55,
+	14, 13, 16, 24, 40, 57, 69, 56,
+	14, 17, 22, 29, 51, 87, 80, 62,
+	18, 22, 37, 56, 68, 109, 103, 77,
+	24, 35, 55, 64, 81, 104, 113, 92,
+	49, 64, 78, 87, 103, 121, 120, 101,
+	72, 92, 95, 98, 112, 100, 103, 99,
+}
+
+// parseJpegSegments 解析 SOS 之前的 JPEG 段，提取量化表与元数据
+func parseJpegSegments(data []byte) jpegInfo {
+	info := jpegInfo{quant: map[int][64]int{}}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			break
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // 填充字节
+			pos++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 { // SOS / EOI
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			break
+		}
+		seg := data[pos+4 : pos+2+length]
+
+		switch {
+		case marker == 0xDB: // DQT
+			for len(seg) > 0 {
+				precision, id := int(seg[0]>>4), int(seg[0]&0x0F)
+				size := 64
+				if precision == 1 {
+					size = 128
+				}
+				if len(seg) < 1+size {
+					break
+				}
+				var table [64]int
+				for i := 0; i < 64; i++ {
+					if precision == 1 {
+						table[jpegUnzigzag[i]] = int(binary.BigEndian.Uint16(seg[1+2*i:]))
+					} else {
+						table[jpegUnzigzag[i]] = int(seg[1+i])
+					}
+				}
+				info.quant[id] = table
+				seg = seg[1+size:]
+			}
+		case marker >= 0xC0 && marker <= 0xC2: // SOF0/1/2
+			if len(seg) >= 9 {
+				info.lumaTableID = int(seg[8])
+			}
+		case marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")):
+			info.exif = seg[6:]
+		case marker == 0xE1 && bytes.HasPrefix(seg, []byte("http://ns.adobe.com/xap/1.0/")):
+			info.xmp = seg
+		case marker == 0xED && bytes.HasPrefix(seg, []byte("Photoshop 3.0")):
+			info.photoshopIRB = true
+		}
+		pos += 2 + length
+	}
+	return info
+}
+
+// estimateJpegQuality 以 IJG 标准表反推亮度量化表对应的质量
+func estimateJpegQuality(table [64]int) int {
+	bestQuality, bestDiff := 0, math.MaxInt
+	for q := 1; q <= 100; q++ {
+		scale := 200 - 2*q
+		if q < 50 {
+			scale = 5000 / q
+		}
+		diff := 0
+		for i, v := range jpegStdLumaQuant {
+			t := (v*scale + 50) / 100
+			if t < 1 {
+				t = 1
+			} else if t > 255 {
+				t = 255
+			}
+			d := t - table[i]
+			if d < 0 {
+				d = -d
+			}
+			diff += d
+		}
+		if diff < bestDiff {
+			bestQuality, bestDiff = q, diff
+		}
+	}
+	return bestQuality
+}
+
+// --- EXIF / PNG 元数据 ---
+
+const (
+	exifTagSoftware         = 0x0131
+	exifTagDateTime         = 0x0132
+	exifTagExifIFD          = 0x8769
+	exifTagDateTimeOriginal = 0x9003
+)
+
+// parseExifTags 读取 IFD0 与 Exif 子 IFD 中的 ASCII 标签
+func parseExifTags(tiff []byte) map[int]string {
+	tags := map[int]string{}
+	if len(tiff) < 8 {
+		return tags
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return tags
+	}
+
+	var readIFD func(offset int, depth int)
+	readIFD = func(offset int, depth int) {
+		if depth > 2 || offset < 8 || offset+2 > len(tiff) {
+			return
+		}
+		count := int(order.Uint16(tiff[offset:]))
+		for i := 0; i < count; i++ {
+			entry := offset + 2 + i*12
+			if entry+12 > len(tiff) {
+				return
+			}
+			tag := int(order.Uint16(tiff[entry:]))
+			typ := order.Uint16(tiff[entry+2:])
+			n := int(order.Uint32(tiff[entry+4:]))
+			switch {
+			case tag == exifTagExifIFD:
+				readIFD(int(order.Uint32(tiff[entry+8:])), depth+1)
+			case typ == 2 && n > 0: // ASCII
+				start := entry + 8
+				if n > 4 {
+					start = int(order.Uint32(tiff[entry+8:]))
+				}
+				if start < 0 || start+n > len(tiff) {
+					continue
+				}
+				tags[tag] = strings.TrimSpace(strings.TrimRight(string(tiff[start:start+n]), "\x00"))
+			}
+		}
+	}
+	readIFD(int(order.Uint32(tiff[4:])), 0)
+	return tags
+}
+
+// parsePngText 读取 PNG 文本块中的 Software 与 XMP
+func parsePngText(data []byte) (string, []byte) {
+	var software string
+	var xmp []byte
+	pos := 8
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunkType := string(data[pos+4 : pos+8])
+		if length < 0 || pos+12+length > len(data) {
+			break
+		}
+		body := data[pos+8 : pos+8+length]
+		if chunkType == "tEXt" || chunkType == "iTXt" {
+			if sep := bytes.IndexByte(body, 0); sep > 0 {
+				keyword := string(body[:sep])
+				switch keyword {
+				case "Software":
+					software = strings.Trim(string(body[sep+1:]), "\x00 ")
+				case "XML:com.adobe.xmp":
+					xmp = body[sep+1:]
+				}
+			}
+		}
+		if chunkType == "IEND" {
+			break
+		}
+		pos += 12 + length
+	}
+	return software, xmp
+}
+
+// --- 像素级分析 ---
+
+// lumaPlane 取出亮度平面（仅支持 YCbCr 与灰度图）
+func lumaPlane(img image.Image) ([]uint8, int, int) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	switch m := img.(type) {
+	case *image.YCbCr:
+		out := make([]uint8, w*h)
+		for y := 0; y < h; y++ {
+			copy(out[y*w:(y+1)*w], m.Y[y*m.YStride:y*m.YStride+w])
+		}
+		return out, w, h
+	case *image.Gray:
+		out := make([]uint8, w*h)
+		for y := 0; y < h; y++ {
+			copy(out[y*w:(y+1)*w], m.Pix[y*m.Stride:y*m.Stride+w])
+		}
+		return out, w, h
+	}
+	return nil, 0, 0
+}
+
+// dctCos 预计算 cos((2x+1)uπ/16)
+var dctCos = func() [8][8]float64 {
+	var t [8][8]float64
+	for u := 0; u < 8; u++ {
+		for x := 0; x < 8; x++ {
+			t[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / 16)
+		}
+	}
+	return t
+}()
+
+// doubleQuantizationScore 统计低频 DCT 系数直方图中的非单调点与缺口数量
+// 单次压缩的系数按量化步长取整后近似拉普拉斯分布（随 |k| 单调递减），二次压缩会产生周期性缺口或峰值
+func doubleQuantizationScore(luma []uint8, w, h int, table [64]int) int {
+	const maxK = 12
+	const maxBlocks = 20000
+	coeffs := [][2]int{{0, 1}, {1, 0}, {1, 1}, {0, 2}, {2, 0}}
+	hist := make([][maxK + 2]int, len(coeffs))
+
+	bw, bh := w/8, h/8
+	total := bw * bh
+	if total == 0 {
+		return 0
+	}
+	step := 1
+	if total > maxBlocks {
+		step = total / maxBlocks
+	}
+
+	var block [8][8]float64
+	for n := 0; n < total; n += step {
+		bx, by := (n%bw)*8, (n/bw)*8
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				block[y][x] = float64(luma[(by+y)*w+bx+x]) - 128
+			}
+		}
+		for ci, c := range coeffs {
+			u, v := c[0], c[1]
+			sum := 0.0
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					sum += block[y][x] * dctCos[u][x] * dctCos[v][y]
+				}
+			}
+			cu, cv := 1.0, 1.0
+			if u == 0 {
+				cu = 1 / math.Sqrt2
+			}
+			if v == 0 {
+				cv = 1 / math.Sqrt2
+			}
+			q := table[v*8+u]
+			if q <= 1 {
+				continue
+			}
+			k := int(math.Abs(math.Round(sum * cu * cv / 4 / float64(q))))
+			if k <= maxK+1 {
+				hist[ci][k]++
+			}
+		}
+	}
+
+	anomalies := 0
+	for _, hs := range hist {
+		for k := 1; k <= maxK; k++ {
+			prev, cur, next := hs[k-1], hs[k], hs[k+1]
+			if prev < 20 {
+				break
+			}
+			switch {
+			case float64(cur) < 0.15*float64(prev+next)/2: // 缺口
+				anomalies++
+			case cur >= 20 && float64(next) > 1.3*float64(cur): // 非单调上升
+				anomalies++
+			}
+		}
+	}
+	return anomalies
+}
+
+// gridMisalignment 检测 8x8 块效应的相位，若最强块边界不在 (0,0) 则说明图片被裁剪后重新压缩
+func gridMisalignment(luma []uint8, w, h int) (int, int, bool) {
+	if w < 64 || h < 64 {
+		return 0, 0, false
+	}
+	phase := func(horizontal bool) (int, bool) {
+		var sums [8]float64
+		var counts [8]int
+		rowStep := 1
+		if h*w > 4_000_000 {
+			rowStep = 2
+		}
+		if horizontal {
+			for y := 0; y < h; y += rowStep {
+				row := luma[y*w : (y+1)*w]
+				for x := 0; x+1 < w; x++ {
+					d := math.Abs(float64(row[x+1]) - float64(row[x]))
+					sums[(x+1)%8] += d
+					counts[(x+1)%8]++
+				}
+			}
+		} else {
+			for y := 0; y+1 < h; y++ {
+				for x := 0; x < w; x += rowStep {
+					d := math.Abs(float64(luma[(y+1)*w+x]) - float64(luma[y*w+x]))
+					sums[(y+1)%8] += d
+					counts[(y+1)%8]++
+				}
+			}
+		}
+		var avg [8]float64
+		mean := 0.0
+		for i := range sums {
+			if counts[i] > 0 {
+				avg[i] = sums[i] / float64(counts[i])
+			}
+			mean += avg[i] / 8
+		}
+		best := 0
+		for i := range avg {
+			if avg[i] > avg[best] {
+				best = i
+			}
+		}
+		strong := mean > 0 && avg[best] > 1.15*mean && avg[best] > 1.3*avg[0]
+		return best, strong
+	}
+
+	dx, okX := phase(true)
+	dy, okY := phase(false)
+	if (okX && dx != 0) || (okY && dy != 0) {
+		if !okX {
+			dx = 0
+		}
+		if !okY {
+			dy = 0
+		}
+		return dx, dy, true
+	}
+	return 0, 0, false
+}
+
+// timestampELAAnomalies 对常见时间戳位置（截图顶部状态栏、相机水印所在的底部）做误差水平分析
+func timestampELAAnomalies(img image.Image, luma []uint8, w, h int) []string {
+	const blockSize = 16
+	if w < blockSize*8 || h < blockSize*8 {
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		return nil
+	}
+	recompressed, err := jpeg.Decode(buf)
+	if err != nil {
+		return nil
+	}
+	reLuma, rw, rh := lumaPlane(recompressed)
+	if reLuma == nil || rw != w || rh != h {
+		return nil
+	}
+
+	bw, bh := w/blockSize, h/blockSize
+	errs := make([]float64, bw*bh)
+	for by := 0; by < bh; by++ {
+		for bx := 0; bx < bw; bx++ {
+			sum := 0.0
+			for y := by * blockSize; y < (by+1)*blockSize; y++ {
+				for x := bx * blockSize; x < (bx+1)*blockSize; x++ {
+					sum += math.Abs(float64(luma[y*w+x]) - float64(reLuma[y*w+x]))
+				}
+			}
+			errs[by*bw+bx] = sum / (blockSize * blockSize)
+		}
+	}
+
+	sorted := append([]float64(nil), errs...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	mean, variance := 0.0, 0.0
+	for _, e := range errs {
+		mean += e
+	}
+	mean /= float64(len(errs))
+	for _, e := range errs {
+		variance += (e - mean) * (e - mean)
+	}
+	std := math.Sqrt(variance / float64(len(errs)))
+	hot := mean + 3*std
+
+	regions := []struct {
+		name           string
+		fromRow, toRow int
+		fromCol        int
+	}{
+		{"顶部", 0, int(math.Ceil(float64(bh) * 0.08)), 0},
+		{"右下角", int(float64(bh) * 0.85), bh, bw / 2},
+	}
+
+	var anomalies []string
+	for _, r := range regions {
+		hotCount, count, sum := 0, 0, 0.0
+		for by := r.fromRow; by < r.toRow; by++ {
+			for bx := r.fromCol; bx < bw; bx++ {
+				e := errs[by*bw+bx]
+				sum += e
+				count++
+				if e > hot {
+					hotCount++
+				}
+			}
+		}
+		if count == 0 {
+			continue
+		}
+		regionMean := sum / float64(count)
+		if float64(hotCount)/float64(count) >= 0.15 && regionMean > 2*median && regionMean > 1.5*mean {
+			anomalies = append(anomalies, r.name)
+		}
+	}
+	return anomalies
+}
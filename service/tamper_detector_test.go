@@ -0,0 +1,244 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"testing"
+)
+
+// testPhoto 带噪声的渐变图，固定随机种子保证检测结果稳定
+func testPhoto(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	r := rand.New(rand.NewSource(1))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8((x*255/w + y*255/h) / 2)
+			n := uint8(r.Intn(24))
+			img.Set(x, y, color.RGBA{v + n, v/2 + n, 255 - v + n, 255})
+		}
+	}
+	return img
+}
+
+func encodeJpeg(t *testing.T, img image.Image, quality int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// resaveJpeg 以 fromQuality 压缩后解码，再以 toQuality 保存（量化表与实际系数分布不一致）
+func resaveJpeg(t *testing.T, img image.Image, fromQuality, toQuality int, crop image.Point) []byte {
+	t.Helper()
+	decoded, err := jpeg.Decode(bytes.NewReader(encodeJpeg(t, img, fromQuality)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crop != (image.Point{}) {
+		b := decoded.Bounds()
+		decoded = decoded.(interface {
+			SubImage(image.Rectangle) image.Image
+		}).SubImage(image.Rectangle{Min: crop, Max: b.Max})
+	}
+	return encodeJpeg(t, decoded, toQuality)
+}
+
+// withSegments 在 SOI 之后插入 APPn 段
+func withSegments(data []byte, segments ...[]byte) []byte {
+	out := append([]byte(nil), data[:2]...)
+	for _, seg := range segments {
+		out = append(out, seg...)
+	}
+	return append(out, data[2:]...)
+}
+
+func appSegment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// exifSegment 构造 APP1 Exif：IFD0 写入 Software/DateTime，Exif 子 IFD 写入 DateTimeOriginal（空串表示省略）
+func exifSegment(software, modified, original string) []byte {
+	type entry struct {
+		tag   uint16
+		value string
+	}
+	var ifd0, sub []entry
+	if software != "" {
+		ifd0 = append(ifd0, entry{exifTagSoftware, software})
+	}
+	if modified != "" {
+		ifd0 = append(ifd0, entry{exifTagDateTime, modified})
+	}
+	if original != "" {
+		sub = append(sub, entry{exifTagDateTimeOriginal, original})
+	}
+
+	order := binary.LittleEndian
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	var values []byte
+	// 先计算两个 IFD 的大小，字符串值统一放在 IFD 之后
+	ifd0Size := 2 + 12*(len(ifd0)+1) + 4
+	subOffset := 8 + ifd0Size
+	subSize := 2 + 12*len(sub) + 4
+	valueOffset := subOffset + subSize
+
+	writeIFD := func(entries []entry, pointer uint32) []byte {
+		count := len(entries)
+		if pointer > 0 {
+			count++
+		}
+		ifd := order.AppendUint16(nil, uint16(count))
+		for _, e := range entries {
+			v := append([]byte(e.value), 0)
+			ifd = order.AppendUint16(ifd, e.tag)
+			ifd = order.AppendUint16(ifd, 2) // ASCII
+			ifd = order.AppendUint32(ifd, uint32(len(v)))
+			ifd = order.AppendUint32(ifd, uint32(valueOffset+len(values)))
+			values = append(values, v...)
+		}
+		if pointer > 0 {
+			ifd = order.AppendUint16(ifd, exifTagExifIFD)
+			ifd = order.AppendUint16(ifd, 4) // LONG
+			ifd = order.AppendUint32(ifd, 1)
+			ifd = order.AppendUint32(ifd, pointer)
+		}
+		return order.AppendUint32(ifd, 0)
+	}
+	tiff = append(tiff, writeIFD(ifd0, uint32(subOffset))...)
+	for len(tiff) < subOffset {
+		tiff = append(tiff, 0)
+	}
+	tiff = append(tiff, writeIFD(sub, 0)...)
+	tiff = append(tiff, values...)
+	return appSegment(0xE1, append([]byte("Exif\x00\x00"), tiff...))
+}
+
+func xmpSegment(body string) []byte {
+	return appSegment(0xE1, append([]byte("http://ns.adobe.com/xap/1.0/\x00"), body...))
+}
+
+func photoshopSegment() []byte {
+	return appSegment(0xED, []byte("Photoshop 3.0\x008BIM\x04\x04\x00\x00\x00\x00\x00\x00"))
+}
+
+// pngWithSoftware 在 IHDR 之后插入 tEXt Software 块
+func pngWithSoftware(t *testing.T, software string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 16, 16))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	body := append([]byte("Software\x00"), software...)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(body)))
+	chunk = append(chunk, "tEXt"...)
+	chunk = append(chunk, body...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	ihdrEnd := 8 + 12 + 13
+	return append(append(append([]byte(nil), data[:ihdrEnd]...), chunk...), data[ihdrEnd:]...)
+}
+
+func TestTamperDetectorInspect(t *testing.T) {
+	photo := testPhoto(256, 256)
+	clean := encodeJpeg(t, photo, 90)
+	resaved := resaveJpeg(t, photo, 40, 90, image.Point{})
+	cropped := resaveJpeg(t, photo, 40, 90, image.Point{X: 3, Y: 5})
+
+	tests := []struct {
+		name     string
+		data     []byte
+		score    float64
+		level    string
+		marker   bool
+		software string
+	}{
+		{"未编辑的 JPEG", clean, 0, "low", false, ""},
+		{"相机软件标识不计入", withSegments(clean, exifSegment("iPhone 15 Pro 18.0", "", "")), 0, "low", false, "iPhone 15 Pro 18.0"},
+		{"编辑软件标识", withSegments(clean, exifSegment("Adobe Photoshop 25.0 (Windows)", "", "")), 0.5, "medium", true, "Adobe Photoshop 25.0 (Windows)"},
+		{"EXIF 修改时间晚于拍摄时间", withSegments(clean, exifSegment("", "2025:10:15 20:00:00", "2025:10:15 18:32:00")), 0.2, "low", true, ""},
+		{"XMP 编辑历史", withSegments(clean, xmpSegment(`<x:xmpmeta><xmpMM:History/></x:xmpmeta>`)), 0.3, "low", true, ""},
+		{"编辑软件 + Photoshop 资源块 + 编辑历史", withSegments(clean,
+			exifSegment("Adobe Photoshop 25.0 (Windows)", "", ""),
+			xmpSegment(`<x:xmpmeta><xmpMM:History/></x:xmpmeta>`),
+			photoshopSegment()), 0.72, "high", true, "Adobe Photoshop 25.0 (Windows)"},
+		{"二次压缩", resaved, 0.35, "low", false, ""},
+		{"裁剪后重新保存", cropped, 0.3, "low", false, ""},
+		{"二次压缩 + 编辑软件", withSegments(resaved, exifSegment("Snapseed 2.0", "", "")), 0.68, "medium", true, "Snapseed 2.0"},
+		{"二次压缩 + 编辑软件 + Photoshop 资源块", withSegments(resaved, exifSegment("Snapseed 2.0", "", ""), photoshopSegment()), 0.74, "high", true, "Snapseed 2.0"},
+		{"PNG 编辑软件标识", pngWithSoftware(t, "GIMP 2.10.36"), 0.5, "medium", true, "GIMP 2.10.36"},
+		{"PNG 截图", pngWithSoftware(t, "Android Screenshot"), 0, "low", false, "Android Screenshot"},
+	}
+	td := NewTamperDetector(0)
+	for _, tt := range tests {
+		got := td.Inspect(tt.data)
+		if !got.Checked {
+			t.Errorf("%s: 未完成检测: %s", tt.name, got.ErrorMessage)
+			continue
+		}
+		if got.RiskScore != tt.score || got.RiskLevel != tt.level || got.EditMarker != tt.marker || got.Software != tt.software {
+			t.Errorf("%s: score=%.2f level=%s marker=%v software=%q signals=%v, want score=%.2f level=%s marker=%v software=%q",
+				tt.name, got.RiskScore, got.RiskLevel, got.EditMarker, got.Software, got.Signals, tt.score, tt.level, tt.marker, tt.software)
+		}
+	}
+}
+
+func TestTamperDetectorCompressionOnlyNeverHigh(t *testing.T) {
+	// 二次压缩后在顶部与右下角时间戳区域贴入未压缩的噪声块：压缩与 ELA 信号合计超过 0.7，
+	// 但没有元数据/编辑软件痕迹（转发截图同样会出现这些信号），风险级别不应为 high
+	base := mustDecodeJpeg(t, resaveJpeg(t, testPhoto(256, 256), 40, 90, image.Point{}))
+	canvas := image.NewRGBA(base.Bounds())
+	draw.Draw(canvas, canvas.Bounds(), base, image.Point{}, draw.Src)
+	r := rand.New(rand.NewSource(2))
+	for _, patch := range []image.Rectangle{image.Rect(128, 4, 224, 28), image.Rect(160, 228, 250, 252)} {
+		for y := patch.Min.Y; y < patch.Max.Y; y++ {
+			for x := patch.Min.X; x < patch.Max.X; x++ {
+				v := uint8(r.Intn(256))
+				canvas.Set(x, y, color.RGBA{v, v, v, 255})
+			}
+		}
+	}
+	data := encodeJpeg(t, canvas, 85)
+
+	td := NewTamperDetector(0)
+	got := td.Inspect(data)
+	if got.RiskScore != 0.77 || got.RiskLevel != "medium" || got.EditMarker || len(got.Signals) != 3 {
+		t.Errorf("仅压缩痕迹: score=%.2f level=%s marker=%v signals=%v, want score=0.77 level=medium marker=false",
+			got.RiskScore, got.RiskLevel, got.EditMarker, got.Signals)
+	}
+	got = td.Inspect(withSegments(data, exifSegment("Meitu 9.0", "", "")))
+	if got.RiskScore != 0.88 || got.RiskLevel != "high" || !got.EditMarker {
+		t.Errorf("压缩痕迹 + 编辑软件: score=%.2f level=%s marker=%v, want score=0.88 level=high marker=true",
+			got.RiskScore, got.RiskLevel, got.EditMarker)
+	}
+}
+
+func TestTamperDetectorPixelLimit(t *testing.T) {
+	data := withSegments(encodeJpeg(t, testPhoto(64, 64), 90), exifSegment("Adobe Photoshop 25.0", "", ""))
+	got := NewTamperDetector(1000).Inspect(data)
+	if got.Checked || got.ErrorMessage == "" {
+		t.Errorf("超过像素上限应跳过像素级分析: %+v", got)
+	}
+	if !got.EditMarker || got.RiskScore != 0.5 {
+		t.Errorf("元数据检测不受像素上限影响: score=%.2f marker=%v", got.RiskScore, got.EditMarker)
+	}
+}
+
+func mustDecodeJpeg(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
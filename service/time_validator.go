@@ -3,7 +3,10 @@ package service
 import (
 	"fmt"
 	"log"
+	"my-ai-app/calendar"
 	"my-ai-app/model"
+	"my-ai-app/rules"
+	"my-ai-app/timezone"
 	"strings"
 	"time"
 )
@@ -14,13 +17,13 @@ type TimeValidator struct{}
 // NewTimeValidator 创建时间验证器
 func NewTimeValidator() *TimeValidator { return &TimeValidator{} }
 
-// ValidateApplicationTime 验证申请时间的有效性
-func (tv *TimeValidator) ValidateApplicationTime(appData model.ApplicationData) (*model.TimeValidationResult, error) {
+// ValidateApplicationTime 验证申请时间的有效性，attendance 为 OA 当天考勤（未接入 OA 或获取失败时为 nil）
+func (tv *TimeValidator) ValidateApplicationTime(appData model.ApplicationData, attendance *model.AttendanceData) (*model.TimeValidationResult, error) {
 	log.Printf("开始验证申请时间 - UserId: %s, Date: %s, Time: %s, Type: %s",
 		appData.UserId, appData.ApplicationDate, appData.ApplicationTime, appData.ApplicationType)
 
-	// 不再调用 OA 接口，直接基于申请数据进行基础校验
-	result := tv.createBasicValidationResult(appData)
+	// 工作日按日历判断，班次按排班（OA 下发了当天标准上下班时间时以 OA 为准），OA 打卡记录用于补充说明
+	result := tv.createBasicValidationResult(appData, attendance)
 
 	log.Printf("时间验证完成 - IsValid: %v, IsWorkDay: %v, IsLate: %v, RiskLevel: %s",
 		result.IsValid, result.IsWorkDay, result.IsLate, result.RiskLevel)
@@ -28,9 +31,6 @@ func (tv *TimeValidator) ValidateApplicationTime(appData model.ApplicationData)
 	return result, nil
 }
 
-// performTimeValidation 执行具体的时间验证逻辑
-// 已不再依赖 OA 返回的字段，保留 isLate 等工具函数用于基础判断
-
 // isLate 判断申请时间是否会导致迟到
 func (tv *TimeValidator) isLate(applicationTime, standardTime string) bool {
 	// 解析时间
@@ -45,29 +45,80 @@ func (tv *TimeValidator) isLate(applicationTime, standardTime string) bool {
 	return appTime.After(standardTimeParsed)
 }
 
-// createBasicValidationResult 创建基础验证结果（当无法获取考勤数据时）
-func (tv *TimeValidator) createBasicValidationResult(appData model.ApplicationData) *model.TimeValidationResult {
-	// 基础的工作日判断
-	parsedDate, err := time.Parse("2006-01-02", appData.ApplicationDate)
-	isWorkDay := true
-	if err == nil {
-		weekday := parsedDate.Weekday()
-		isWorkDay = weekday >= time.Monday && weekday <= time.Friday
+// createBasicValidationResult 创建基础验证结果，attendance 为 nil 时按无法获取考勤数据处理
+func (tv *TimeValidator) createBasicValidationResult(appData model.ApplicationData, attendance *model.AttendanceData) *model.TimeValidationResult {
+	// 按节假日日历判断工作日（含法定节假日与调休上班日）；多日申请看范围内是否有工作日
+	// 未提供申请日期时按员工所在时区的当天判断
+	loc := timezone.Default().For(appData.UserId, appData.Department)
+	day, ok := calendar.Default().LookupString(appData.ApplicationDate)
+	if appData.ApplicationDate == "" {
+		day, ok = calendar.Default().Today(loc), true
+	}
+	isWorkDay := !ok || day.IsWorkday()
+	workingDays, multiDay := rules.WorkingDays(appData)
+	if multiDay {
+		isWorkDay = workingDays > 0
+	}
+
+	// 按员工当天的班次（OA 下发了标准上下班时间时以 OA 为准）判断补卡时间是否迟到/早退，补卡时间按规则引擎的方式换算（"下午6点"、"次日00:30"、带日期/时区的写法）
+	shift := rules.ShiftFor(appData, attendance)
+	var isLate, isEarlyLeave bool
+	var unparsed []string
+	if appData.ApplicationType == "补打卡" {
+		clockIn := -1
+		if strings.TrimSpace(appData.StartTime) != "" {
+			if m, ok := rules.PunchMinutes(appData, shift, loc, appData.StartTime); ok {
+				clockIn, isLate = m, !shift.OnTimeIn(m)
+			} else {
+				unparsed = append(unparsed, appData.StartTime)
+			}
+		}
+		if strings.TrimSpace(appData.EndTime) != "" {
+			if m, ok := rules.PunchMinutes(appData, shift, loc, appData.EndTime); ok {
+				isEarlyLeave = !shift.OnTimeOut(m, clockIn)
+			} else {
+				unparsed = append(unparsed, appData.EndTime)
+			}
+		}
 	}
 
 	result := &model.TimeValidationResult{
-		IsValid:   true,
-		IsWorkDay: isWorkDay,
-		IsLate:    false,
-		RiskLevel: "medium",
+		IsValid:      true,
+		IsWorkDay:    isWorkDay,
+		IsLate:       isLate,
+		IsEarlyLeave: isEarlyLeave,
+		RiskLevel:    "medium",
+		Shift:        shift.Describe(),
+		Timezone:     loc.String(),
 	}
 
-	if !isWorkDay {
+	switch {
+	case !isWorkDay && appData.ApplicationType == "加班":
+		// 非工作日加班属正常情况
+		result.RiskLevel = "low"
+		result.Details = fmt.Sprintf("申请日期 %s", day.Describe())
+	case !isWorkDay && multiDay:
+		result.IsValid = false
+		result.RiskLevel = "high"
+		result.Suggestion = "申请日期范围内没有工作日，请确认申请日期"
+		result.Details = fmt.Sprintf("申请日期 %s~%s 内没有工作日", appData.StartDate, appData.EndDate)
+	case !isWorkDay:
 		result.IsValid = false
 		result.RiskLevel = "high"
 		result.Suggestion = "申请时间为非工作日，请确认申请类型"
-		result.Details = fmt.Sprintf("申请日期 %s 不是工作日", appData.ApplicationDate)
-	} else {
+		result.Details = fmt.Sprintf("申请日期为 %s", day.Describe())
+	case isLate || isEarlyLeave:
+		result.RiskLevel = "high"
+		result.Suggestion = "补卡时间不在班次规定时间内，补卡后仍为迟到/早退，请确认"
+		result.Details = fmt.Sprintf("班次 %s，补卡时间 %s", shift.Describe(), displayPunchTimes(appData.StartTime, appData.EndTime))
+	case len(unparsed) > 0:
+		result.RiskLevel = "high"
+		result.Suggestion = "补卡时间无法识别或不在申请日期当天，无法判断是否迟到/早退，请人工确认"
+		result.Details = fmt.Sprintf("班次 %s，无法识别的补卡时间 %s", shift.Describe(), strings.Join(unparsed, "、"))
+	case attendance != nil:
+		result.RiskLevel = "low"
+		result.Details = describeAttendance(attendance)
+	default:
 		result.Suggestion = "无法获取考勤数据，请人工审核"
 		result.Details = "系统无法验证考勤情况，建议人工审核"
 	}
@@ -75,6 +126,30 @@ func (tv *TimeValidator) createBasicValidationResult(appData model.ApplicationDa
 	return result
 }
 
+// displayPunchTimes 补卡时间的展示文本
+func displayPunchTimes(startTime, endTime string) string {
+	switch {
+	case startTime != "" && endTime != "":
+		return startTime + "~" + endTime
+	case startTime != "":
+		return "上班 " + startTime
+	}
+	return "下班 " + endTime
+}
+
+// describeAttendance OA 考勤的展示文本，如 "OA 考勤：缺卡，打卡记录 08:55"
+func describeAttendance(a *model.AttendanceData) string {
+	status := a.Status
+	if status == "" {
+		status = "状态未知"
+	}
+	punches := "无"
+	if all := a.AllPunches(); len(all) > 0 {
+		punches = strings.Join(all, "、")
+	}
+	return fmt.Sprintf("OA 考勤：%s，打卡记录 %s", status, punches)
+}
+
 // GenerateValidationMessage 生成验证消息
 func (tv *TimeValidator) GenerateValidationMessage(result *model.TimeValidationResult) string {
 	var messages []string
@@ -90,6 +165,9 @@ func (tv *TimeValidator) GenerateValidationMessage(result *model.TimeValidationR
 	if result.IsLate {
 		messages = append(messages, "申请时间可能导致迟到")
 	}
+	if result.IsEarlyLeave {
+		messages = append(messages, "申请时间可能导致早退")
+	}
 
 	// 风险级别
 	switch result.RiskLevel {
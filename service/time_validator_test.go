@@ -0,0 +1,49 @@
+package service
+
+import (
+	"my-ai-app/model"
+	"testing"
+)
+
+func TestValidateApplicationTimePunchFormats(t *testing.T) {
+	tests := []struct {
+		start, end    string
+		late, early   bool
+		wantRiskLevel string
+	}{
+		{"", "下午6点", false, false, "medium"},
+		{"", "下午5点", false, true, "high"},
+		{"", "次日00:30", false, false, "medium"},
+		{"", "2025-10-15 17:30", false, true, "high"},
+		{"2025-10-15 09:30", "", true, false, "high"},
+		{"9点", "18：00", false, false, "medium"},
+		{"早上九点半", "", true, false, "high"},
+		{"", "2025-10-13 18:00", false, false, "high"}, // 不在申请日期当天，无法判断
+		{"稍后补", "", false, false, "high"},
+	}
+	tv := NewTimeValidator()
+	for _, tt := range tests {
+		app := model.ApplicationData{ApplicationType: "补打卡", ApplicationDate: "2025-10-15", StartTime: tt.start, EndTime: tt.end}
+		got, err := tv.ValidateApplicationTime(app, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.IsLate != tt.late || got.IsEarlyLeave != tt.early || got.RiskLevel != tt.wantRiskLevel {
+			t.Errorf("start=%q end=%q: late=%v early=%v risk=%s, want late=%v early=%v risk=%s (%s)",
+				tt.start, tt.end, got.IsLate, got.IsEarlyLeave, got.RiskLevel, tt.late, tt.early, tt.wantRiskLevel, got.Details)
+		}
+	}
+}
+
+func TestValidateApplicationTimeUsesOAStandardTimes(t *testing.T) {
+	app := model.ApplicationData{ApplicationType: "补打卡", ApplicationDate: "2025-10-15", EndTime: "17:30"}
+	attendance := &model.AttendanceData{StandardInTime: "08:30", StandardOutTime: "17:30", Status: "缺卡"}
+	got, _ := NewTimeValidator().ValidateApplicationTime(app, attendance)
+	if got.IsEarlyLeave || got.RiskLevel != "low" {
+		t.Errorf("OA 标准下班时间 17:30 时补卡 17:30 不应早退: %+v", got)
+	}
+	got, _ = NewTimeValidator().ValidateApplicationTime(app, &model.AttendanceData{Status: "缺卡"})
+	if !got.IsEarlyLeave {
+		t.Errorf("OA 未下发标准时间时应按排班 18:00 判断早退: %+v", got)
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"my-ai-app/model"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VerdictCache LLM 判定结果缓存
+// 内存 LRU + TTL，可选落盘到 JSON 文件以便重启后继续命中（后台合并落盘，见 fileFlusher）
+type VerdictCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	filePath string // 为空表示不落盘
+	ll       *list.List
+	items    map[string]*list.Element
+
+	flusher *fileFlusher // 为 nil 表示不落盘
+}
+
+// verdictCacheEntry 缓存条目（同时作为落盘格式）
+type verdictCacheEntry struct {
+	Key       string              `json:"key"`
+	Data      model.ExtractedData `json:"data"`
+	RequestId string              `json:"request_id"`
+	ExpiresAt time.Time           `json:"expires_at"`
+}
+
+// NewVerdictCache 创建缓存，filePath 非空时从文件恢复未过期条目
+func NewVerdictCache(capacity int, ttl time.Duration, filePath string) *VerdictCache {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	c := &VerdictCache{
+		capacity: capacity,
+		ttl:      ttl,
+		filePath: filePath,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+	if filePath != "" {
+		if err := c.load(); err != nil {
+			log.Printf("加载判定缓存文件失败: %v", err)
+		}
+		c.flusher = newFileFlusher(filePath, "判定缓存", persistFlushInterval, func() interface{} {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.snapshotLocked()
+		})
+	}
+	return c
+}
+
+// Close 写入尚未落盘的缓存条目（服务退出时调用）
+func (c *VerdictCache) Close() {
+	if c.flusher != nil {
+		c.flusher.Close()
+	}
+}
+
+// VerdictCacheKey 由图片标识与影响判定的全部输入计算缓存键
+// imageID 为图片内容哈希（文件、base64 与 URL 图片均按内容计算）
+func VerdictCacheKey(imageID string, provider string, modelName string, promptVersion string, appData model.ApplicationData, needImageValidation bool) string {
+	parts := []string{
+		imageID, provider, modelName, promptVersion,
+		appData.ApplicationType, appData.Alias, appData.ApplicationDate, appData.StartDate, appData.EndDate,
+		appData.ApplicationTime, appData.StartTime, appData.EndTime,
+		strings.Join(appData.AttendanceInfo, ","),
+		fmt.Sprintf("%v", needImageValidation),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ContentHash 计算图片内容哈希
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Get 查询缓存，返回数据副本与原始请求ID
+func (c *VerdictCache) Get(key string) (*model.ExtractedData, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, "", false
+	}
+	entry := elem.Value.(*verdictCacheEntry)
+	if c.ttl > 0 && time.Now().After(entry.ExpiresAt) {
+		c.ll.Remove(elem)
+		delete(c.items, key)
+		return nil, "", false
+	}
+	c.ll.MoveToFront(elem)
+	data := entry.Data
+	data.CandidateTimes = append([]string(nil), entry.Data.CandidateTimes...)
+	data.OcrDates = append([]string(nil), entry.Data.OcrDates...)
+	data.OcrTimes = append([]string(nil), entry.Data.OcrTimes...)
+	return &data, entry.RequestId, true
+}
+
+// Put 写入缓存，超出容量时淘汰最久未使用的条目
+func (c *VerdictCache) Put(key string, data *model.ExtractedData, requestId string) {
+	if data == nil {
+		return
+	}
+	c.mu.Lock()
+	entry := &verdictCacheEntry{
+		Key:       key,
+		Data:      *data,
+		RequestId: requestId,
+		ExpiresAt: time.Now().Add(c.ttl),
+	}
+	entry.Data.CandidateTimes = append([]string(nil), data.CandidateTimes...)
+	entry.Data.OcrDates = append([]string(nil), data.OcrDates...)
+	entry.Data.OcrTimes = append([]string(nil), data.OcrTimes...)
+	if elem, ok := c.items[key]; ok {
+		elem.Value = entry
+		c.ll.MoveToFront(elem)
+	} else {
+		c.items[key] = c.ll.PushFront(entry)
+		for c.ll.Len() > c.capacity {
+			oldest := c.ll.Back()
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*verdictCacheEntry).Key)
+		}
+	}
+	c.mu.Unlock()
+
+	if c.flusher != nil {
+		c.flusher.MarkDirty()
+	}
+}
+
+// snapshotLocked 按从旧到新的顺序复制未过期条目（调用方需持有锁）
+func (c *VerdictCache) snapshotLocked() []verdictCacheEntry {
+	now := time.Now()
+	out := make([]verdictCacheEntry, 0, c.ll.Len())
+	for elem := c.ll.Back(); elem != nil; elem = elem.Prev() {
+		entry := elem.Value.(*verdictCacheEntry)
+		if c.ttl > 0 && now.After(entry.ExpiresAt) {
+			continue
+		}
+		out = append(out, *entry)
+	}
+	return out
+}
+
+// load 从文件恢复缓存（文件中的顺序为从旧到新）
+func (c *VerdictCache) load() error {
+	data, err := os.ReadFile(c.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var entries []verdictCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("解析缓存文件失败: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for i := range entries {
+		entry := entries[i]
+		if c.ttl > 0 && now.After(entry.ExpiresAt) {
+			continue
+		}
+		c.items[entry.Key] = c.ll.PushFront(&entry)
+	}
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*verdictCacheEntry).Key)
+	}
+	log.Printf("判定缓存已从 %s 恢复 %d 条记录", c.filePath, c.ll.Len())
+	return nil
+}
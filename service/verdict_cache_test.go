@@ -0,0 +1,28 @@
+package service
+
+import (
+	"my-ai-app/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestVerdictCachePersistsOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c := NewVerdictCache(10, time.Hour, path)
+	for i := 0; i < 5; i++ {
+		c.Put("k", &model.ExtractedData{RequestTime: "18:32"}, "req-1")
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Fatalf("写入后不应立即同步落盘")
+	}
+	c.Close()
+
+	reloaded := NewVerdictCache(10, time.Hour, path)
+	defer reloaded.Close()
+	data, requestId, ok := reloaded.Get("k")
+	if !ok || requestId != "req-1" || data.RequestTime != "18:32" {
+		t.Fatalf("重启后未恢复缓存: %v %q %v", ok, requestId, data)
+	}
+}
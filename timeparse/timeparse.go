@@ -0,0 +1,432 @@
+// Package timeparse 中文日期/时间解析，供规则引擎核对与 LLM 输出规整共用
+// 支持 "18:32"、全角 "18：32"、"18时32分"、"下午6点32"、"六点半"、"6:32 PM"、"24:30"、"次日00:30"、"昨天 18:32"、
+// "2025年10月15日"、"十月十五日"、"10-15" 等写法；相对日期（昨天、次日等）按参照日期（申请日期）换算
+package timeparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"my-ai-app/timezone"
+)
+
+// Time 从文本中解析出的时间
+type Time struct {
+	Hour      int            // 24 小时制；"24:30"、"晚上12点" 等次日写法的小时数 >= 24
+	Minute    int            //
+	DayOffset int            // 相对日期词表示的天数（昨天 -1、次日 +1），Relative 为 false 时为 0
+	Relative  bool           // 是否带相对日期词（今天、昨天、次日等）
+	Date      string         // 时间前的日期文字（如 "2025-10-16"、"10月16日"），未标明时为空
+	Suffix    string         // 时间后的文字（如时区标注 " UTC+8"）
+	Zone      *time.Location // 紧跟时间的时区标注（"09:00 UTC+8"、"2025-10-15T01:00:00Z"），未标注时为 nil
+}
+
+// Clock HH:mm（次日写法保留 24 之后的小时数）
+func (t Time) Clock() string {
+	return fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
+}
+
+// Offset 相对参照日期的天数：相对日期词直接换算，带日期的按 ResolveDate 推断（ref 为零值时无法推断日期），未标明日期为 0
+func (t Time) Offset(ref time.Time) (int, bool) {
+	switch {
+	case t.Relative:
+		return t.DayOffset, true
+	case t.Date == "":
+		return 0, true
+	case ref.IsZero():
+		return 0, false
+	}
+	day, ok := ResolveDate(t.Date, ref)
+	if !ok {
+		return 0, false
+	}
+	return DaysBetween(ref, day), true
+}
+
+const cnDigits = "零〇一二两三四五六七八九十"
+
+var cnDigitValues = map[rune]int{
+	'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+var (
+	// timeRegex 时段 + 时钟（H:mm[:ss] 或 H点/时[mm分|半|整]）+ AM/PM
+	timeRegex = regexp.MustCompile(`(?i)(凌晨|早上|早晨|清晨|上午|中午|午后|下午|傍晚|晚上|夜里|夜间|半夜|深夜)?\s*` +
+		`(?:(\d{1,2})\s*:\s*(\d{2})(?::\d{2})?|([\d` + cnDigits + `]{1,3})\s*[点點时時]\s*(?:([\d` + cnDigits + `]{1,3})\s*(分)?|(半)|(整))?)` +
+		`(?:\s*([ap])\.?m\b\.?)?`)
+	clockRegex    = regexp.MustCompile(`\d{1,2}\s*:\s*\d{2}(?:\s*:\s*\d{2})?`)
+	relativeRegex = regexp.MustCompile(`今天|今日|昨天|昨日|前天|明天|明日|次日|翌日|第[二2]天|后天|\+1`)
+)
+
+// relativeDays 相对日期词对应的天数
+var relativeDays = map[string]int{
+	"今天": 0, "今日": 0, "昨天": -1, "昨日": -1, "前天": -2,
+	"明天": 1, "明日": 1, "次日": 1, "翌日": 1, "第二天": 1, "第2天": 1, "+1": 1, "后天": 2,
+}
+
+// ParseTime 解析文本中第一个时间
+func ParseTime(s string) (Time, bool) {
+	s = normalizeWidth(strings.TrimSpace(s))
+	for _, idx := range findTimes(s) {
+		if t, ok := parseMatch(s, idx); ok {
+			prefix := s[:idx[0]]
+			if rel := relativeRegex.FindString(prefix); rel != "" {
+				t.DayOffset, t.Relative = relativeDays[rel], true
+				prefix = strings.Replace(prefix, rel, "", 1)
+			}
+			t.Date = strings.TrimSpace(prefix)
+			t.Suffix = s[idx[1]:]
+			t.Zone = parseZone(s[idx[0]:idx[1]], t.Suffix)
+			return t, true
+		}
+	}
+	return Time{}, false
+}
+
+// parseZone 时间后的时区标注；带秒的 ISO 8601 时间戳允许紧跟纯偏移（"16:50:00-08:00"），
+// 其余写法的纯偏移需以空白分隔，避免将时间段 "09:00-12:00" 的 "-12:00" 误作时区
+func parseZone(clock, suffix string) *time.Location {
+	parse := timezone.ParseZone
+	if strings.Count(clock, ":") == 2 {
+		parse = timezone.ParseOffset
+	}
+	if loc, ok := parse(suffix); ok {
+		return loc
+	}
+	return nil
+}
+
+// FindClocks 提取文本中的全部当天时间（HH:mm，去重并保持出现顺序），用于 OCR 文本行等
+func FindClocks(s string) []string {
+	s = normalizeWidth(s)
+	var out []string
+	seen := map[string]bool{}
+	for _, idx := range findTimes(s) {
+		if t, ok := parseMatch(s, idx); ok && t.Hour < 24 && !seen[t.Clock()] {
+			seen[t.Clock()] = true
+			out = append(out, t.Clock())
+		}
+	}
+	return out
+}
+
+// findTimes 返回 timeRegex 的全部有效匹配。被 parseMatch 否决的匹配（如 "快一点 09:00" 中的 "一点 09"）
+// 从其小时之后继续查找，避免吞掉紧随其后的真实时间
+func findTimes(s string) [][]int {
+	var out [][]int
+	for start := 0; start < len(s); {
+		idx := timeRegex.FindStringSubmatchIndex(s[start:])
+		if idx == nil {
+			break
+		}
+		for i := range idx {
+			if idx[i] >= 0 {
+				idx[i] += start
+			}
+		}
+		if _, ok := parseMatch(s, idx); ok {
+			out = append(out, idx)
+			start = idx[1]
+			if idx[1] == idx[0] {
+				start++
+			}
+			continue
+		}
+		// 否决的匹配从小时（中文数字或时钟的时）之后继续
+		switch {
+		case idx[9] >= 0:
+			start = idx[9]
+		case idx[5] >= 0:
+			start = idx[5]
+		default:
+			start = idx[1]
+		}
+	}
+	return out
+}
+
+// parseMatch 将 timeRegex 的一次匹配换算为 24 小时制
+func parseMatch(s string, idx []int) (Time, bool) {
+	group := func(i int) string {
+		if idx[2*i] < 0 {
+			return ""
+		}
+		return s[idx[2*i]:idx[2*i+1]]
+	}
+	period, ampm := group(1), strings.ToLower(group(9))
+	var hour, minute int
+	if group(2) != "" {
+		hour, _ = strconv.Atoi(group(2))
+		minute, _ = strconv.Atoi(group(3))
+	} else {
+		var ok bool
+		if hour, ok = parseNumber(group(4)); !ok {
+			return Time{}, false
+		}
+		switch {
+		case group(7) != "":
+			minute = 30
+		case group(5) != "":
+			if minute, ok = parseNumber(group(5)); !ok {
+				return Time{}, false
+			}
+		}
+		// 中文数字的 "一点"、"两点" 等常见于口语（"快一点"），需有时段、分钟或 半/整 才视为时间
+		if !isArabic(group(4)) && period == "" && ampm == "" && group(6) == "" && group(7) == "" && group(8) == "" {
+			return Time{}, false
+		}
+	}
+
+	switch {
+	case ampm == "p" || period == "下午" || period == "午后" || period == "傍晚" || period == "晚上" || period == "夜里" || period == "夜间" || period == "深夜":
+		if hour < 12 {
+			hour += 12
+		} else if hour == 12 && ampm == "" && period != "下午" && period != "午后" {
+			hour = 24 // 晚上12点 即次日 00:00
+		}
+	case period == "中午":
+		if hour < 11 {
+			hour += 12
+		}
+	case period == "半夜":
+		if hour == 12 {
+			hour = 24
+		}
+	case ampm == "a" || period == "凌晨" || period == "上午" || period == "早上" || period == "早晨" || period == "清晨":
+		if hour == 12 {
+			hour = 0
+		}
+	}
+	if hour > 47 || minute > 59 || (ampm != "" && hour > 24) {
+		return Time{}, false
+	}
+	return Time{Hour: hour, Minute: minute}, true
+}
+
+// NormalizeTime 将时间文本规整为规则引擎使用的写法：当天为 "HH:mm"，带日期或相对日期的为 "yyyy-MM-dd HH:mm"
+// （ref 为零值时次日写作 "次日HH:mm"），标注了时区的保留时区（如 "01:00 UTC"）。无法解析时原样返回 false
+func NormalizeTime(s string, ref time.Time) (string, bool) {
+	t, ok := ParseTime(s)
+	if !ok {
+		return s, false
+	}
+	out := t.Clock()
+	if t.Relative || t.Date != "" {
+		offset, ok := t.Offset(ref)
+		switch {
+		case ok && !ref.IsZero():
+			out = formatDate(ref.AddDate(0, 0, offset)) + " " + out
+		case t.Relative && offset == 1:
+			out = "次日" + out
+		case t.Date != "" && !t.Relative:
+			if date, ok := NormalizeDate(t.Date, ref); ok {
+				out = date + " " + out
+			}
+		case offset != 0:
+			return s, false
+		}
+	}
+	if t.Zone != nil {
+		out += " " + t.Zone.String()
+	}
+	return out, true
+}
+
+// NormalizeDate 将日期文本规整为 "yyyy-MM-dd"（相对日期与仅有月日的日期按 ref 推断；ref 为零值时仅有月日的写作 "MM-dd"）
+// 无法解析时原样返回 false
+func NormalizeDate(s string, ref time.Time) (string, bool) {
+	if day, ok := ResolveDate(s, ref); ok {
+		return formatDate(day), true
+	}
+	if year, month, day, ok := DateParts(s); ok && year == 0 {
+		return fmt.Sprintf("%02d-%02d", month, day), true
+	}
+	return s, false
+}
+
+// DateParts 提取日期中的数字：有年份时返回 (年, 月, 日)，仅月日时年份为 0
+// 支持紧接 年/月/日/号 的中文数字（"二〇二五年十月十五日"），其余中文数字（"第一人民医院"、"星期三"）不参与提取
+// 时钟（"09:30"、"09:30-18:00"）先剔除再提取，仅有时钟的文本不视为日期
+func DateParts(s string) (year, month, day int, ok bool) {
+	s = clockRegex.ReplaceAllString(arabizeDate(normalizeWidth(s)), " ")
+	var nums []int
+	n, inNum := 0, false
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			n = n*10 + int(r-'0')
+			inNum = true
+			continue
+		}
+		if inNum {
+			nums = append(nums, n)
+			n, inNum = 0, false
+		}
+	}
+	if inNum {
+		nums = append(nums, n)
+	}
+	switch {
+	case len(nums) >= 3 && nums[0] >= 1000:
+		year, month, day = nums[0], nums[1], nums[2]
+	case len(nums) >= 2 && nums[0] <= 12:
+		month, day = nums[0], nums[1]
+	default:
+		return 0, 0, 0, false
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return 0, 0, 0, false
+	}
+	return year, month, day, true
+}
+
+// ResolveDate 将日期文本解析为具体日期（与 ref 同一时区，ref 为零值时为 UTC）
+// 相对日期词（昨天、次日等）按 ref 换算；仅有月日时参照 ref 推断年份：取 ref 前后一年中离 ref 最近的一天（跨年时 12-31 对应上一年）
+func ResolveDate(s string, ref time.Time) (time.Time, bool) {
+	if rel := relativeRegex.FindString(s); rel != "" {
+		if ref.IsZero() {
+			return time.Time{}, false
+		}
+		return ref.AddDate(0, 0, relativeDays[rel]), true
+	}
+	year, month, day, ok := DateParts(s)
+	if !ok {
+		return time.Time{}, false
+	}
+	loc := time.UTC
+	if !ref.IsZero() {
+		loc = ref.Location()
+	}
+	if year > 0 {
+		return time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc), true
+	}
+	if ref.IsZero() {
+		return time.Time{}, false
+	}
+	var best time.Time
+	for _, y := range []int{ref.Year() - 1, ref.Year(), ref.Year() + 1} {
+		candidate := time.Date(y, time.Month(month), day, 0, 0, 0, 0, loc)
+		if best.IsZero() || abs(candidate.Sub(ref)) < abs(best.Sub(ref)) {
+			best = candidate
+		}
+	}
+	return best, true
+}
+
+// DaysBetween 从 a 所在日期到 b 所在日期相差的天数（按各自时区的日历日期，不受夏令时影响）
+func DaysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return int(time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC).Sub(time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
+
+// normalizeWidth 全角数字与冒号转为半角
+func normalizeWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '０' && r <= '９':
+			return r - '０' + '0'
+		case r == '：':
+			return ':'
+		}
+		return r
+	}, s)
+}
+
+// arabizeDate 将紧接 年/月/日/号 的中文数字串转为阿拉伯数字："十月十五日" -> "10月15日"、"二〇二五年" -> "2025年"
+func arabizeDate(s string) string {
+	var b strings.Builder
+	var run []rune
+	flush := func(next rune) {
+		if len(run) == 0 {
+			return
+		}
+		if n, ok := parseNumber(string(run)); ok && strings.ContainsRune("年月日号", next) {
+			b.WriteString(strconv.Itoa(n))
+		} else {
+			b.WriteString(string(run))
+		}
+		run = run[:0]
+	}
+	for _, r := range s {
+		if strings.ContainsRune(cnDigits, r) {
+			run = append(run, r)
+			continue
+		}
+		flush(r)
+		b.WriteRune(r)
+	}
+	flush(0)
+	return b.String()
+}
+
+// parseNumber 解析阿拉伯数字或中文数字（含 "十"，如 "十八"、"三十二"；不含 "十" 时逐位读，如 "二〇二五"）
+func parseNumber(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if isArabic(s) {
+		n, err := strconv.Atoi(s)
+		return n, err == nil
+	}
+	runes := []rune(s)
+	if tens := strings.IndexRune(s, '十'); tens >= 0 {
+		pos := len([]rune(s[:tens]))
+		high, low := 1, 0
+		if pos > 1 {
+			return 0, false
+		}
+		if pos == 1 {
+			d, ok := cnDigitValues[runes[0]]
+			if !ok {
+				return 0, false
+			}
+			high = d
+		}
+		switch rest := runes[pos+1:]; len(rest) {
+		case 0:
+		case 1:
+			d, ok := cnDigitValues[rest[0]]
+			if !ok {
+				return 0, false
+			}
+			low = d
+		default:
+			return 0, false
+		}
+		return high*10 + low, true
+	}
+	n := 0
+	for _, r := range runes {
+		d, ok := cnDigitValues[r]
+		if !ok {
+			return 0, false
+		}
+		n = n*10 + d
+	}
+	return n, true
+}
+
+// isArabic 是否全为阿拉伯数字
+func isArabic(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// formatDate 格式化为 yyyy-MM-dd
+func formatDate(t time.Time) string {
+	return fmt.Sprintf("%04d-%02d-%02d", t.Year(), t.Month(), t.Day())
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,172 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+)
+
+var ref = time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
+
+func TestResolveDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // 空串表示无法解析
+	}{
+		{"2025-10-15", "2025-10-15"},
+		{"2025年10月14日", "2025-10-14"},
+		{"10月14日", "2025-10-14"},
+		{"10-14", "2025-10-14"},
+		{"12-31", "2025-12-31"},
+		{"十月十四日", "2025-10-14"},
+		{"二〇二五年十月十四日", "2025-10-14"},
+		{"昨天", "2025-10-14"},
+		{"前天", "2025-10-13"},
+		{"次日", "2025-10-16"},
+		{"第一人民医院 10月15日", "2025-10-15"},
+		{"星期三 10月15日", "2025-10-15"},
+		{"周一 10-15", "2025-10-15"},
+		{"十一月一日", "2025-11-01"},
+		{"2025-10-16 00:30", "2025-10-16"},
+		{"2025-10-15T01:00:00Z", "2025-10-15"},
+		{"09:30", ""},
+		{"09:30-18:00", ""},
+		{"18：32", ""},
+		{"未知", ""},
+	}
+	for _, tt := range tests {
+		got, ok := ResolveDate(tt.in, ref)
+		switch {
+		case tt.want == "" && ok:
+			t.Errorf("ResolveDate(%q) = %s, want 无法解析", tt.in, formatDate(got))
+		case tt.want != "" && (!ok || formatDate(got) != tt.want):
+			t.Errorf("ResolveDate(%q) = %s, %v, want %s", tt.in, formatDate(got), ok, tt.want)
+		}
+	}
+}
+
+func TestNormalizeDateWithoutRef(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"10月14日", "10-14"},
+		{"2025/10/14", "2025-10-14"},
+		{"昨天", "昨天"},
+	}
+	for _, tt := range tests {
+		if got, _ := NormalizeDate(tt.in, time.Time{}); got != tt.want {
+			t.Errorf("NormalizeDate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeZone(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // 空串表示未标注时区
+	}{
+		{"01:00 UTC", "UTC"},
+		{"08:55 UTC+8", "UTC+8"},
+		{"2025-10-15T01:00:00Z", "UTC"},
+		{"2025-10-14T16:50:00-08:00", "UTC-8"},
+		{"09:00 +08:00", "UTC+8"},
+		{"09:00-12:00", ""},
+		{"08:55-18:00", ""},
+		{"09:00~12:00", ""},
+		{"09:00 打卡 UTC+8", ""},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTime(tt.in)
+		if !ok {
+			t.Errorf("ParseTime(%q) 无法解析", tt.in)
+			continue
+		}
+		switch {
+		case tt.want == "" && got.Zone != nil:
+			t.Errorf("ParseTime(%q).Zone = %s, want nil", tt.in, got.Zone)
+		case tt.want != "" && (got.Zone == nil || got.Zone.String() != tt.want):
+			t.Errorf("ParseTime(%q).Zone = %v, want %s", tt.in, got.Zone, tt.want)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in     string
+		clock  string // 空串表示不应识别为时间
+		offset int
+	}{
+		{"18:32", "18:32", 0},
+		{"18：32", "18:32", 0},
+		{"１８：３２", "18:32", 0},
+		{"18时32分", "18:32", 0},
+		{"十八时三十二分", "18:32", 0},
+		{"下午6点32", "18:32", 0},
+		{"下午六点三十二分", "18:32", 0},
+		{"六点半", "06:30", 0},
+		{"晚上8点整", "20:00", 0},
+		{"6:32 PM", "18:32", 0},
+		{"6:32 p.m.", "18:32", 0},
+		{"12:05 AM", "00:05", 0},
+		{"中午12点半", "12:30", 0},
+		{"中午1点", "13:00", 0},
+		{"凌晨1点", "01:00", 0},
+		{"晚上12点", "24:00", 0},
+		{"24:30", "24:30", 0},
+		{"次日00:30", "00:30", 1},
+		{"第二天 01:10", "01:10", 1},
+		{"+1 00:15", "00:15", 1},
+		{"昨天 18:32", "18:32", -1},
+		{"前天 9点", "09:00", -2},
+		{"12:30:45", "12:30", 0},
+		{"09:00-18:00", "09:00", 0},
+		{"快一点", "", 0},
+		{"2小时", "", 0},
+		{"第一人民医院", "", 0},
+		{"48:00", "", 0},
+		{"18:60", "", 0},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTime(tt.in)
+		switch {
+		case tt.clock == "" && ok:
+			t.Errorf("ParseTime(%q) = %s, want 无法识别", tt.in, got.Clock())
+		case tt.clock != "" && (!ok || got.Clock() != tt.clock || got.DayOffset != tt.offset):
+			t.Errorf("ParseTime(%q) = %s (offset %d), %v, want %s (offset %d)", tt.in, got.Clock(), got.DayOffset, ok, tt.clock, tt.offset)
+		}
+	}
+}
+
+func TestFindClocks(t *testing.T) {
+	got := FindClocks("上班 下午6:32 打卡成功，12:30:45 同步；快一点 09:00~18:00")
+	want := []string{"18:32", "12:30", "09:00", "18:00"}
+	if len(got) != len(want) {
+		t.Fatalf("FindClocks = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("FindClocks = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNormalizeTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		ref  time.Time
+		want string
+	}{
+		{"下午6点32", ref, "18:32"},
+		{"昨天 18:32", ref, "2025-10-14 18:32"},
+		{"次日00:30", ref, "2025-10-16 00:30"},
+		{"次日00:30", time.Time{}, "次日00:30"},
+		{"10月16日 早上8点", ref, "2025-10-16 08:00"},
+		{"10月16日 早上8点", time.Time{}, "10-16 08:00"},
+		{"2025-10-15T01:00:00Z", ref, "2025-10-15 01:00 UTC"},
+		{"01:00 UTC+8", ref, "01:00 UTC+8"},
+		{"昨天 18:32", time.Time{}, "昨天 18:32"},
+		{"未知", ref, "未知"},
+	}
+	for _, tt := range tests {
+		if got, _ := NormalizeTime(tt.in, tt.ref); got != tt.want {
+			t.Errorf("NormalizeTime(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package timezone 公司与各办公地的时区：员工的申请日期、打卡时间与工作日均按其所在时区理解，
+// 证据中标明时区的时间（如 "09:00 UTC+8"、"2025-10-15T01:00:00Z"）换算到员工所在时区后再核对
+// 公司时区由 COMPANY_TIMEZONE 指定（默认 Asia/Shanghai），办公地时区通过 TIMEZONE_CONFIG_PATH 配置（示例见 offices.example.yaml）
+package timezone
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // 容器内可能没有系统时区数据
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCompanyTimezone 未配置 COMPANY_TIMEZONE 时的公司时区
+const DefaultCompanyTimezone = "Asia/Shanghai"
+
+// Office 办公地：按员工 ID 或部门匹配，列表顺序即优先级，应先写员工级再写部门级
+type Office struct {
+	Name        string   `yaml:"name"`
+	Timezone    string   `yaml:"timezone"` // IANA 时区名，如 "Asia/Singapore"，或 "UTC+8" 等固定偏移
+	UserIDs     []string `yaml:"user_ids,omitempty"`
+	Departments []string `yaml:"departments,omitempty"`
+
+	loc *time.Location
+}
+
+// matches 办公地是否适用于该员工
+func (o Office) matches(userID, department string) bool {
+	if len(o.UserIDs) == 0 && len(o.Departments) == 0 {
+		return false
+	}
+	return (len(o.UserIDs) == 0 || contains(o.UserIDs, userID)) &&
+		(len(o.Departments) == 0 || contains(o.Departments, department))
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Config 办公地时区配置文件
+type Config struct {
+	Offices []Office `yaml:"offices"`
+}
+
+// Zones 公司时区与各办公地时区
+type Zones struct {
+	company *time.Location
+	offices []Office
+}
+
+// New 创建时区表，company 为空时使用 Asia/Shanghai
+func New(company string, offices []Office) (*Zones, error) {
+	if company == "" {
+		company = DefaultCompanyTimezone
+	}
+	loc, err := Load(company)
+	if err != nil {
+		return nil, fmt.Errorf("公司时区无效: %w", err)
+	}
+	compiled := make([]Office, 0, len(offices))
+	for _, o := range offices {
+		if o.loc, err = Load(o.Timezone); err != nil {
+			return nil, fmt.Errorf("办公地 %s 的时区无效: %w", o.Name, err)
+		}
+		compiled = append(compiled, o)
+	}
+	return &Zones{company: loc, offices: compiled}, nil
+}
+
+// LoadFile 从 YAML/JSON 文件加载办公地时区
+func LoadFile(path, company string) (*Zones, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取时区配置失败: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析时区配置失败: %w", err)
+	}
+	return New(company, cfg.Offices)
+}
+
+// Company 公司时区
+func (z *Zones) Company() *time.Location { return z.company }
+
+// For 员工所在时区：匹配的第一个办公地，未匹配时为公司时区
+func (z *Zones) For(userID, department string) *time.Location {
+	for _, o := range z.offices {
+		if o.matches(userID, department) {
+			return o.loc
+		}
+	}
+	return z.company
+}
+
+// Load 解析时区：IANA 时区名（如 "Asia/Tokyo"）或固定偏移（"UTC+8"、"GMT-05:30"、"+08:00"、"Z"、"北京时间"）
+func Load(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if loc, n, ok := parseOffset(name); ok && n == len(name) {
+		return loc, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("无法识别的时区 %q", name)
+	}
+	return loc, nil
+}
+
+// zoneRegex 紧接时间的时区标注：UTC/GMT（可带偏移）、纯偏移 ±hh:mm、Z、北京时间
+var zoneRegex = regexp.MustCompile(`(?i)^(?:(?:UTC|GMT)\s*(?:([+-])\s*(\d{1,2})(?::?(\d{2}))?)?|([+-])(\d{2}):?(\d{2})\b|Z\b|北京时间)`)
+
+// ParseZone 提取紧跟在时间之后的时区标注（如 "09:00 UTC+8" 的 " UTC+8"），未标注时返回 false
+// 纯偏移需与时间以空白分隔（"09:00 +08:00"），避免将时间段 "09:00-12:00" 的 "-12:00" 误作时区；
+// ISO 8601 时间戳（"2025-10-14T16:50:00-08:00"）的偏移由调用方以 ParseOffset 解析
+func ParseZone(suffix string) (*time.Location, bool) {
+	trimmed := strings.TrimLeft(suffix, " \t")
+	if trimmed == suffix && (strings.HasPrefix(suffix, "+") || strings.HasPrefix(suffix, "-")) {
+		return nil, false
+	}
+	loc, _, ok := parseOffset(trimmed)
+	return loc, ok
+}
+
+// ParseOffset 解析以时区标注开头的文本（含不带空白的纯偏移，如 ISO 8601 的 "-08:00"）
+func ParseOffset(s string) (*time.Location, bool) {
+	loc, _, ok := parseOffset(s)
+	return loc, ok
+}
+
+// parseOffset 解析开头的时区标注，返回时区与标注长度
+func parseOffset(s string) (*time.Location, int, bool) {
+	m := zoneRegex.FindStringSubmatch(s)
+	if m == nil {
+		return nil, 0, false
+	}
+	if strings.Contains(m[0], "北京时间") {
+		return time.FixedZone("UTC+8", 8*3600), len(m[0]), true
+	}
+	sign, hours, minutes := m[1], m[2], m[3]
+	if m[4] != "" {
+		sign, hours, minutes = m[4], m[5], m[6]
+	}
+	if sign == "" {
+		return time.UTC, len(m[0]), true
+	}
+	h, _ := strconv.Atoi(hours)
+	mm, _ := strconv.Atoi(minutes)
+	if h > 14 || mm > 59 {
+		return nil, 0, false
+	}
+	offset := h*3600 + mm*60
+	name := fmt.Sprintf("UTC%s%d", sign, h)
+	if mm > 0 {
+		name += fmt.Sprintf(":%02d", mm)
+	}
+	if sign == "-" {
+		offset = -offset
+	}
+	return time.FixedZone(name, offset), len(m[0]), true
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultZones, _ = New("", nil)
+)
+
+// Default 全局时区表，默认所有人为公司时区 Asia/Shanghai
+func Default() *Zones {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultZones
+}
+
+// SetDefault 替换全局时区表（启动时按 COMPANY_TIMEZONE / TIMEZONE_CONFIG_PATH 加载后调用）
+func SetDefault(z *Zones) {
+	if z == nil {
+		return
+	}
+	defaultMu.Lock()
+	defaultZones = z
+	defaultMu.Unlock()
+}
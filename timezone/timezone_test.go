@@ -0,0 +1,75 @@
+package timezone
+
+import "testing"
+
+func TestParseZone(t *testing.T) {
+	tests := []struct {
+		suffix string
+		want   string // 空串表示未标注时区
+	}{
+		{" UTC", "UTC"},
+		{"UTC", "UTC"},
+		{" UTC+8", "UTC+8"},
+		{" GMT-05:30", "UTC-5:30"},
+		{" +08:00", "UTC+8"},
+		{"Z", "UTC"},
+		{" 北京时间", "UTC+8"},
+		{"", ""},
+		{"-12:00", ""},    // 时间段 09:00-12:00
+		{"-18:00", ""},    // 时间段 08:55-18:00
+		{" - 12:00", ""},  // 时间段 09:00 - 12:00
+		{" 打卡 UTC+8", ""}, // 时区须紧跟时间
+		{" 张三 +08:00", ""},
+		{" Zhang San", ""},
+		{" UTC+15", ""},
+	}
+	for _, tt := range tests {
+		loc, ok := ParseZone(tt.suffix)
+		switch {
+		case tt.want == "" && ok:
+			t.Errorf("ParseZone(%q) = %s, want 未标注", tt.suffix, loc)
+		case tt.want != "" && (!ok || loc.String() != tt.want):
+			t.Errorf("ParseZone(%q) = %v, %v, want %s", tt.suffix, loc, ok, tt.want)
+		}
+	}
+}
+
+func TestParseOffsetISO(t *testing.T) {
+	loc, ok := ParseOffset("-08:00")
+	if !ok || loc.String() != "UTC-8" {
+		t.Errorf("ParseOffset(-08:00) = %v, %v", loc, ok)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for _, name := range []string{"Asia/Tokyo", "UTC+8", "+08:00", "GMT-05:30", "Z", "北京时间"} {
+		if _, err := Load(name); err != nil {
+			t.Errorf("Load(%q): %v", name, err)
+		}
+	}
+	for _, name := range []string{"Mars/Olympus", "UTC+8 办公室"} {
+		if _, err := Load(name); err == nil {
+			t.Errorf("Load(%q) 应失败", name)
+		}
+	}
+}
+
+func TestFor(t *testing.T) {
+	z, err := New("", []Office{
+		{Name: "东京", Timezone: "Asia/Tokyo", UserIDs: []string{"u1"}},
+		{Name: "新加坡研发", Timezone: "Asia/Singapore", Departments: []string{"研发"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ user, dept, want string }{
+		{"u1", "研发", "Asia/Tokyo"},
+		{"u2", "研发", "Asia/Singapore"},
+		{"u2", "财务", DefaultCompanyTimezone},
+	}
+	for _, tt := range tests {
+		if got := z.For(tt.user, tt.dept).String(); got != tt.want {
+			t.Errorf("For(%s, %s) = %s, want %s", tt.user, tt.dept, got, tt.want)
+		}
+	}
+}